@@ -0,0 +1,97 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Sum returns the sum of the column values of entities matching the condition, zero if there are none.
+func Sum[V, T any](ctx context.Context, r *Relation[T], column string, cond Cond) (V, error) {
+	return aggregate[V](ctx, r, "SUM(%s)", column, cond)
+}
+
+// Avg returns the average of the column values of entities matching the condition, zero if there are none.
+func Avg[V, T any](ctx context.Context, r *Relation[T], column string, cond Cond) (V, error) {
+	return aggregate[V](ctx, r, "AVG(%s)", column, cond)
+}
+
+// Min returns the minimum of the column values of entities matching the condition, zero if there are none.
+func Min[V, T any](ctx context.Context, r *Relation[T], column string, cond Cond) (V, error) {
+	return aggregate[V](ctx, r, "MIN(%s)", column, cond)
+}
+
+// Max returns the maximum of the column values of entities matching the condition, zero if there are none.
+func Max[V, T any](ctx context.Context, r *Relation[T], column string, cond Cond) (V, error) {
+	return aggregate[V](ctx, r, "MAX(%s)", column, cond)
+}
+
+// CountDistinct counts distinct values of the column of entities matching the condition.
+func (r *Relation[T]) CountDistinct(ctx context.Context, column string, cond Cond) (int64, error) {
+	return aggregate[int64](ctx, r, "COUNT(DISTINCT %s)", column, cond)
+}
+
+// GroupCount counts entities matching the condition grouped by the column values.
+func (r *Relation[T]) GroupCount(ctx context.Context, column string, cond Cond) (map[any]int64, error) {
+	col, ok := r.M.Column(column)
+	if !ok {
+		return nil, fmt.Errorf("group count: unknown column: %s", column)
+	}
+	query := r.countByQ.Copy()
+	query.Select(col.Identifier(), "COUNT(*)")
+	args, expr := cond.Split()
+	for _, e := range expr {
+		query.AndWhere(e)
+	}
+	query.GroupBy(col.Identifier())
+
+	rows, err := r.reader(ctx, "group_count").QueryContext(ctx, query.ToSQL(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("db group count query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	res := make(map[any]int64)
+	for rows.Next() {
+		var (
+			key   any
+			count int64
+		)
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, fmt.Errorf("db group count scan: %w", err)
+		}
+		// []byte keys are not comparable
+		if b, ok := key.([]byte); ok {
+			key = string(b)
+		}
+		res[key] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db group count rows: %w", classifyError(err))
+	}
+
+	return res, nil
+}
+
+// aggregate returns the result of the aggregate expression over the column of entities matching the condition.
+// The expression is a format string with the quoted column placeholder, e.g. "SUM(%s)".
+func aggregate[V, T any](ctx context.Context, r *Relation[T], expr string, column string, cond Cond) (V, error) {
+	var res sql.Null[V]
+	col, ok := r.M.Column(column)
+	if !ok {
+		return res.V, fmt.Errorf("aggregate: unknown column: %s", column)
+	}
+	query := r.countByQ.Copy()
+	query.Select(fmt.Sprintf(expr, col.Identifier()))
+	args, where := cond.Split()
+	for _, e := range where {
+		query.AndWhere(e)
+	}
+
+	row := r.reader(ctx, "aggregate").QueryRowContext(ctx, query.ToSQL(), args...)
+	if err := row.Scan(&res); err != nil {
+		return res.V, fmt.Errorf("db aggregate: %w", classifyError(err))
+	}
+
+	return res.V, nil
+}
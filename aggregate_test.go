@@ -0,0 +1,87 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_Aggregates(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT SUM("id") FROM "entities" WHERE "name" = $1`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(6))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT AVG("id") FROM "entities"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"avg"}).AddRow([]byte("2.5")))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT MIN("created") FROM "entities"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(now))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT MAX("id") FROM "entities"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(DISTINCT "name") FROM "entities"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	if sum, err := Sum[int64](ctx, rel, "id", Cond{Eq("name", "Test Name")}); err != nil || sum != 6 {
+		t.Fatalf("unexpected sum: %v, %v", sum, err)
+	}
+	if avg, err := Avg[float64](ctx, rel, "id", nil); err != nil || avg != 2.5 {
+		t.Fatalf("unexpected avg: %v, %v", avg, err)
+	}
+	if m, err := Min[time.Time](ctx, rel, "created", nil); err != nil || !m.Equal(now) {
+		t.Fatalf("unexpected min: %v, %v", m, err)
+	}
+	if m, err := Max[int64](ctx, rel, "id", nil); err != nil || m != 0 {
+		t.Fatalf("unexpected max: %v, %v", m, err)
+	}
+	if n, err := rel.CountDistinct(ctx, "name", nil); err != nil || n != 2 {
+		t.Fatalf("unexpected count distinct: %v, %v", n, err)
+	}
+	if _, err = Sum[int64](ctx, rel, "amount", nil); err == nil {
+		t.Fatalf("expected error for unknown column")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_GroupCount(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "name", COUNT(*) FROM "entities" WHERE "id" > $1 GROUP BY "name"`)).
+		WithArgs(0).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).
+			AddRow([]byte("First"), 2).
+			AddRow([]byte("Second"), 1))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	counts, err := rel.GroupCount(context.Background(), "name", Cond{Gt("id", 0)})
+	if err != nil {
+		t.Fatalf("failed to group count: %v", err)
+	}
+	if len(counts) != 2 || counts["First"] != 2 || counts["Second"] != 1 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package rel
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/lib/pq"
+)
+
+type assocKind int
+
+const (
+	assocHasMany assocKind = iota + 1
+	assocBelongsTo
+)
+
+// Association loads related entities into entities of type E.
+type Association[E any] interface {
+	load(ctx context.Context, r *Relation[E], entities []*E) error
+}
+
+// Assoc is an association of E entities with R entities of the related relation.
+type Assoc[E, R any] struct {
+	kind    assocKind
+	related *Relation[R]
+	fk      string
+	field   string
+}
+
+// HasMany declares that every E entity has many R entities of the related relation,
+// referencing the E primary key by the foreign key column.
+// Loaded entities are stored into the field of type []R or []*R.
+func HasMany[E, R any](related *Relation[R], fk string) *Assoc[E, R] {
+	return &Assoc[E, R]{kind: assocHasMany, related: related, fk: fk}
+}
+
+// BelongsTo declares that every E entity belongs to an R entity of the related relation,
+// referenced by the foreign key column of E.
+// Loaded entities are stored into the field of type R or *R.
+func BelongsTo[E, R any](related *Relation[R], fk string) *Assoc[E, R] {
+	return &Assoc[E, R]{kind: assocBelongsTo, related: related, fk: fk}
+}
+
+// As returns a copy of the association that stores loaded entities into the named field.
+// By default the field is detected by its type.
+func (a *Assoc[E, R]) As(field string) *Assoc[E, R] {
+	cp := *a
+	cp.field = field
+	return &cp
+}
+
+// Preload loads the given associations of found entities with one query per association.
+// It applies to FindWith, FindBy and FindOneBy.
+func Preload[E any](assocs ...Association[E]) FindOption {
+	return func(o *findOptions) {
+		for _, a := range assocs {
+			o.preload = append(o.preload, a)
+		}
+	}
+}
+
+// Load loads the given association of already found entities.
+func (r *Relation[T]) Load(ctx context.Context, assoc Association[T], entities ...*T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	return assoc.load(ctx, r, entities)
+}
+
+// preload loads associations of the find options into the entities.
+func (r *Relation[T]) preload(ctx context.Context, o findOptions, entities []*T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	for _, p := range o.preload {
+		a, ok := p.(Association[T])
+		if !ok {
+			return fmt.Errorf("preload: association %T is not declared for %T", p, entities[0])
+		}
+		if err := a.load(ctx, r, entities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Assoc[E, R]) load(ctx context.Context, r *Relation[E], entities []*E) error {
+	switch a.kind {
+	case assocHasMany:
+		return a.loadHasMany(ctx, r, entities)
+	case assocBelongsTo:
+		return a.loadBelongsTo(ctx, r, entities)
+	}
+	return fmt.Errorf("preload: unknown association kind: %d", a.kind)
+}
+
+// loadHasMany loads related entities referencing the entities primary key.
+func (a *Assoc[E, R]) loadHasMany(ctx context.Context, r *Relation[E], entities []*E) error {
+	if len(r.M.PKColumns()) != 1 {
+		return fmt.Errorf("preload: has many requires a single column primary key")
+	}
+	if _, ok := a.related.M.Column(a.fk); !ok {
+		return fmt.Errorf("preload: unknown foreign key column: %s", a.fk)
+	}
+	field, elemPtr, err := assocField[E, R](a.field, true)
+	if err != nil {
+		return err
+	}
+
+	pk := r.M.PKColumns()[0].name
+	keys := assocKeys(entities, r.M, pk)
+	children, err := a.related.findAny(ctx, a.fk, keys)
+	if err != nil {
+		return err
+	}
+
+	groups := make(map[any][]*R)
+	for i := range children {
+		key := assocKey(getFieldsValues([]string{a.fk}, a.related.M, &children[i])[0])
+		groups[key] = append(groups[key], &children[i])
+	}
+
+	for _, e := range entities {
+		assignMany(e, field, elemPtr, groups[assocKey(getFieldsValues([]string{pk}, r.M, e)[0])])
+	}
+	return nil
+}
+
+// loadBelongsTo loads related entities referenced by the entities foreign key.
+func (a *Assoc[E, R]) loadBelongsTo(ctx context.Context, r *Relation[E], entities []*E) error {
+	if len(a.related.M.PKColumns()) != 1 {
+		return fmt.Errorf("preload: belongs to requires a single column primary key")
+	}
+	if _, ok := r.M.Column(a.fk); !ok {
+		return fmt.Errorf("preload: unknown foreign key column: %s", a.fk)
+	}
+	field, ptr, err := assocField[E, R](a.field, false)
+	if err != nil {
+		return err
+	}
+
+	pk := a.related.M.PKColumns()[0].name
+	keys := assocKeys(entities, r.M, a.fk)
+	owners, err := a.related.findAny(ctx, pk, keys)
+	if err != nil {
+		return err
+	}
+
+	byKey := make(map[any]*R, len(owners))
+	for i := range owners {
+		byKey[assocKey(getFieldsValues([]string{pk}, a.related.M, &owners[i])[0])] = &owners[i]
+	}
+
+	for _, e := range entities {
+		owner, ok := byKey[assocKey(getFieldsValues([]string{a.fk}, r.M, e)[0])]
+		if !ok {
+			continue
+		}
+		dst := reflect.ValueOf(e).Elem().FieldByIndex(field)
+		if ptr {
+			dst.Set(reflect.ValueOf(owner))
+		} else {
+			dst.Set(reflect.ValueOf(owner).Elem())
+		}
+	}
+	return nil
+}
+
+// assignMany stores related entities into the slice field of the entity, an empty slice if there are none.
+func assignMany[E, R any](e *E, field []int, elemPtr bool, related []*R) {
+	dst := reflect.ValueOf(e).Elem().FieldByIndex(field)
+	dst.Set(reflect.MakeSlice(dst.Type(), 0, len(related)))
+	for _, item := range related {
+		v := reflect.ValueOf(item)
+		if !elemPtr {
+			v = v.Elem()
+		}
+		dst.Set(reflect.Append(dst, v))
+	}
+}
+
+// findAny finds all entities whose column value is any of the given values.
+func (r *Relation[T]) findAny(ctx context.Context, column string, values []any) ([]T, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	col, ok := r.M.Column(column)
+	if !ok {
+		return nil, fmt.Errorf("unknown column: %s", column)
+	}
+	query := r.findByQ.Copy()
+	query.AndWhere(col.Identifier() + " = ANY($1)")
+
+	rows, err := r.reader(ctx, "preload").QueryContext(ctx, query.ToSQL(), pq.Array(values))
+	if err != nil {
+		return nil, fmt.Errorf("db preload query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		var entity T
+		if err := scanRow(rows.Scan, r.M, &entity); err != nil {
+			return nil, fmt.Errorf("db preload scan: %w", err)
+		}
+		items = append(items, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db preload rows: %w", classifyError(err))
+	}
+
+	return items, nil
+}
+
+// assocKeys returns distinct non-null values of the column of the entities.
+func assocKeys[E any](entities []*E, m *Metadata[E], column string) []any {
+	seen := make(map[any]struct{}, len(entities))
+	keys := make([]any, 0, len(entities))
+	for _, e := range entities {
+		v := assocKey(getFieldsValues([]string{column}, m, e)[0])
+		if v == nil {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		keys = append(keys, v)
+	}
+	return keys
+}
+
+// assocKey normalizes a key value so that values of different go types (e.g. int and *int64) match.
+func assocKey(v any) any {
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return v
+	}
+	// []byte is not comparable
+	if b, ok := dv.([]byte); ok {
+		return string(b)
+	}
+	return dv
+}
+
+// assocField returns the index of the E field storing R entities and whether it holds pointers.
+// A has many field is []R or []*R, otherwise R or *R. The field is detected by type if name is empty.
+func assocField[E, R any](name string, many bool) ([]int, bool, error) {
+	et, rt := reflect.TypeFor[E](), reflect.TypeFor[R]()
+	match := func(t reflect.Type) (bool, bool) {
+		if many {
+			if t.Kind() != reflect.Slice {
+				return false, false
+			}
+			t = t.Elem()
+		}
+		switch t {
+		case rt:
+			return true, false
+		case reflect.PointerTo(rt):
+			return true, true
+		}
+		return false, false
+	}
+
+	if name != "" {
+		f, ok := et.FieldByName(name)
+		if !ok {
+			return nil, false, fmt.Errorf("preload: unknown field %s.%s", et, name)
+		}
+		ok, ptr := match(f.Type)
+		if !ok {
+			return nil, false, fmt.Errorf("preload: field %s.%s can not store %s", et, name, rt)
+		}
+		return f.Index, ptr, nil
+	}
+
+	var (
+		index []int
+		ptr   bool
+	)
+	for _, f := range reflect.VisibleFields(et) {
+		if ok, p := match(f.Type); ok && f.IsExported() {
+			if index != nil {
+				return nil, false, fmt.Errorf("preload: ambiguous %s fields in %s, use As to choose", rt, et)
+			}
+			index, ptr = f.Index, p
+		}
+	}
+	if index == nil {
+		return nil, false, fmt.Errorf("preload: no field in %s can store %s", et, rt)
+	}
+	return index, ptr, nil
+}
@@ -0,0 +1,148 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type entityUser struct {
+	ID     int64  `db:"id"`
+	Name   string `db:"name"`
+	Orders []*entityOrder
+}
+
+type entityOrder struct {
+	ID     int64  `db:"id"`
+	UserID *int64 `db:"user_id"`
+	User   entityUser
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_PreloadHasMany(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b").AddRow(3, "c"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "user_id" FROM "orders" WHERE "user_id" = ANY($1)`)).
+		WithArgs("{1,2,3}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 1).AddRow(11, 2).AddRow(12, 1))
+
+	users, err := NewRelation[entityUser]("users", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	orders, err := NewRelation[entityOrder]("orders", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	items, err := users.FindBy(context.Background(), nil, nil, Pagination{}, Preload(HasMany[entityUser](orders, "user_id")))
+	if err != nil {
+		t.Fatalf("failed to find by: %v", err)
+	}
+	if len(items[0].Orders) != 2 || items[0].Orders[0].ID != 10 || items[0].Orders[1].ID != 12 {
+		t.Fatalf("unexpected orders of user 1: %v", items[0].Orders)
+	}
+	if len(items[1].Orders) != 1 || items[1].Orders[0].ID != 11 {
+		t.Fatalf("unexpected orders of user 2: %v", items[1].Orders)
+	}
+	if items[2].Orders == nil || len(items[2].Orders) != 0 {
+		t.Fatalf("expected empty orders of user 3: %v", items[2].Orders)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_PreloadBelongsTo(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "user_id" FROM "orders"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 1).AddRow(11, nil).AddRow(12, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "users" WHERE "id" = ANY($1)`)).
+		WithArgs("{1}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "user_id" FROM "orders"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 1))
+
+	users, err := NewRelation[entityUser]("users", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	orders, err := NewRelation[entityOrder]("orders", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	items, err := orders.FindBy(context.Background(), nil, nil, Pagination{}, Preload(BelongsTo[entityOrder](users, "user_id")))
+	if err != nil {
+		t.Fatalf("failed to find by: %v", err)
+	}
+	if items[0].User.Name != "a" || items[2].User.Name != "a" || items[1].User.ID != 0 {
+		t.Fatalf("unexpected users: %v", items)
+	}
+
+	if _, err = orders.FindBy(context.Background(), nil, nil, Pagination{}, Preload(HasMany[entityUser](orders, "user_id"))); err == nil || !strings.Contains(err.Error(), "not declared") {
+		t.Fatalf("expected error for association of another entity")
+	}
+	if err = users.Load(context.Background(), HasMany[entityUser](orders, "order_id"), &entityUser{ID: 1}); err == nil {
+		t.Fatalf("expected error for unknown foreign key")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_FindWithPreload(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "users" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "user_id" FROM "orders" WHERE "user_id" = ANY($1)`)).
+		WithArgs("{1}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(10, 1).AddRow(12, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "users" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "b"))
+
+	users, err := NewRelation[entityUser]("users", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	orders, err := NewRelation[entityOrder]("orders", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	user, err := users.FindWith(ctx, 1, Preload(HasMany[entityUser](orders, "user_id")))
+	if err != nil {
+		t.Fatalf("failed to find with preload: %v", err)
+	}
+	if user.Name != "a" || len(user.Orders) != 2 || user.Orders[1].ID != 12 {
+		t.Fatalf("unexpected user: %v", user)
+	}
+	// without options FindWith is Find
+	if user, err = users.FindWith(ctx, 2); err != nil || user.Name != "b" {
+		t.Fatalf("unexpected user: %v, %v", user, err)
+	}
+	if _, err = users.FindWith(ctx, []any{1, 2}, Select("id")); err == nil {
+		t.Fatalf("expected error for invalid number of primary key columns")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
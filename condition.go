@@ -2,6 +2,7 @@ package rel
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/lib/pq"
@@ -33,6 +34,7 @@ const (
 	opLike
 	opLikeLower
 	opContains
+	opAll
 )
 
 type Cond []Expr
@@ -140,7 +142,7 @@ func (c Cond) Split() ([]any, []string) {
 			expr = append(expr, "LOWER("+column+") LIKE "+ArgsAdd(&args, strings.ToLower("%"+fmt.Sprint(e.arg[0])+"%")))
 		case opContains:
 			expr = append(expr, column+"  @> "+ArgsAdd(&args, pq.Array(e.arg)))
-		case opUnknown:
+		case opAll, opUnknown:
 		}
 	}
 
@@ -215,6 +217,18 @@ func NotAll(column string, args ...any) Expr {
 	}
 }
 
+// All matches all rows, it is required by UpdateBy and DeleteBy to write the whole relation.
+func All() Expr {
+	return Expr{op: opAll}
+}
+
+// all reports whether the condition explicitly matches all rows.
+func (c Cond) all() bool {
+	return slices.ContainsFunc(c, func(e Expr) bool {
+		return e.op == opAll
+	})
+}
+
 func IsNull(column string) Expr {
 	return Expr{
 		op:     opIsNull,
@@ -0,0 +1,76 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+
+	"github.com/lib/pq"
+)
+
+// preparer is implemented by both *sql.DB and *sql.Tx.
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// CopyFrom streams entities into the relation using COPY FROM STDIN and returns the number of copied rows.
+// Columns are copied in the Metadata.InsertColumns order, generated values are not scanned back.
+// BeforeInsert hooks of the entities are called, an error aborts the copy; AfterInsert hooks are not called.
+// COPY requires a transaction: the active one is used if present, otherwise a new one is started.
+// Query hooks are notified once with the "copy_from" operation and the number of copied rows.
+func (r *Relation[T]) CopyFrom(ctx context.Context, entities iter.Seq[*T]) (n int64, err error) {
+	query := pq.CopyIn(r.table, r.M.InsertColumns().Names()...)
+	if r.schema != "" {
+		query = pq.CopyInSchema(r.schema, r.table, r.M.InsertColumns().Names()...)
+	}
+	ctx, done := r.observe(ctx, "copy_from", query)
+	defer func() {
+		done(n, err)
+	}()
+
+	q := r.querier(ctx)
+	if db, ok := q.(*sql.DB); ok {
+		tx, bErr := db.BeginTx(ctx, nil)
+		if bErr != nil {
+			return 0, fmt.Errorf("copy begin tx: %w", bErr)
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+				return
+			}
+			if cErr := tx.Commit(); cErr != nil {
+				n, err = 0, fmt.Errorf("copy commit tx: %w", classifyError(cErr))
+			}
+		}()
+		q = tx
+	}
+	p, ok := q.(preparer)
+	if !ok {
+		return 0, fmt.Errorf("copy: querier %T does not support prepared statements", q)
+	}
+
+	stmt, err := p.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("copy prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	names := r.M.InsertColumns().Names()
+	for e := range entities {
+		if err = beforeInsert(ctx, e); err != nil {
+			return 0, err
+		}
+		r.touchInsert(e)
+		if _, err = stmt.ExecContext(ctx, getFieldsValues(names, r.M, e)...); err != nil {
+			return 0, fmt.Errorf("copy row: %w", classifyError(err))
+		}
+		n++
+	}
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return 0, fmt.Errorf("copy flush: %w", classifyError(err))
+	}
+
+	return n, nil
+}
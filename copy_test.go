@@ -0,0 +1,47 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelation_CopyFrom(t *testing.T) {
+	now := time.Now()
+	entities := []*entitySerialID{
+		{Name: "First", TimeStamps: TimeStamps{Created: now, Updated: now}},
+		{Name: "Second", TimeStamps: TimeStamps{Created: now, Updated: now}},
+	}
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta(`COPY "entities" ("created", "updated", "name") FROM STDIN`))
+	prep.ExpectExec().WithArgs(now, now, "First").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs(now, now, "Second").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithoutArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	n, err := rel.CopyFrom(context.Background(), slices.Values(entities))
+	if err != nil {
+		t.Fatalf("failed to copy entities: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("unexpected number of copied rows: %d", n)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package rel
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes classified into typed errors.
+const (
+	pgCodeUniqueViolation      = "23505"
+	pgCodeForeignKeyViolation  = "23503"
+	pgCodeCheckViolation       = "23514"
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+)
+
+var (
+	// ErrNotFound is returned when no entity matches the query, it wraps sql.ErrNoRows.
+	ErrNotFound = fmt.Errorf("not found: %w", sql.ErrNoRows)
+	// ErrStaleEntity is returned by Update when the entity version does not match the stored one.
+	ErrStaleEntity = errors.New("stale entity")
+	// ErrEmptyCond is returned by UpdateBy and DeleteBy for a condition without expressions,
+	// use All() to write all entities of the relation.
+	ErrEmptyCond = errors.New("empty condition, use All() to write all entities")
+)
+
+// detailKeyRe matches key columns in a postgres error detail, e.g. "Key (email)=(a@b.c) already exists."
+var detailKeyRe = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// ErrUniqueViolation is returned when a unique constraint is violated.
+type ErrUniqueViolation struct {
+	Constraint string
+	Columns    []string
+	Err        *pq.Error
+}
+
+func (e *ErrUniqueViolation) Error() string {
+	return "unique violation: " + e.Constraint
+}
+
+func (e *ErrUniqueViolation) Unwrap() error {
+	return e.Err
+}
+
+// ErrForeignKeyViolation is returned when a foreign key constraint is violated.
+type ErrForeignKeyViolation struct {
+	Constraint string
+	Columns    []string
+	Err        *pq.Error
+}
+
+func (e *ErrForeignKeyViolation) Error() string {
+	return "foreign key violation: " + e.Constraint
+}
+
+func (e *ErrForeignKeyViolation) Unwrap() error {
+	return e.Err
+}
+
+// ErrCheckViolation is returned when a check constraint is violated.
+type ErrCheckViolation struct {
+	Constraint string
+	Err        *pq.Error
+}
+
+func (e *ErrCheckViolation) Error() string {
+	return "check violation: " + e.Constraint
+}
+
+func (e *ErrCheckViolation) Unwrap() error {
+	return e.Err
+}
+
+// ErrSerializationFailure is returned when a transaction could not be serialized or a deadlock was detected.
+// Such transactions can be safely retried.
+type ErrSerializationFailure struct {
+	Err *pq.Error
+}
+
+func (e *ErrSerializationFailure) Error() string {
+	return "serialization failure: " + e.Err.Message
+}
+
+func (e *ErrSerializationFailure) Unwrap() error {
+	return e.Err
+}
+
+// classifyError converts sql.ErrNoRows and postgres errors into typed errors.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch pqErr.Code {
+	case pgCodeUniqueViolation:
+		return &ErrUniqueViolation{Constraint: pqErr.Constraint, Columns: detailColumns(pqErr.Detail), Err: pqErr}
+	case pgCodeForeignKeyViolation:
+		return &ErrForeignKeyViolation{Constraint: pqErr.Constraint, Columns: detailColumns(pqErr.Detail), Err: pqErr}
+	case pgCodeCheckViolation:
+		return &ErrCheckViolation{Constraint: pqErr.Constraint, Err: pqErr}
+	case pgCodeSerializationFailure, pgCodeDeadlockDetected:
+		return &ErrSerializationFailure{Err: pqErr}
+	}
+	return err
+}
+
+// detailColumns returns key columns from a postgres error detail.
+func detailColumns(detail string) []string {
+	m := detailKeyRe.FindStringSubmatch(detail)
+	if len(m) < 2 {
+		return nil
+	}
+	cols := strings.Split(m[1], ", ")
+	for i, c := range cols {
+		cols[i] = strings.Trim(c, `"`)
+	}
+	return cols
+}
@@ -0,0 +1,107 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(err error) bool
+	}{
+		{
+			name: "no rows",
+			err:  sql.ErrNoRows,
+			check: func(err error) bool {
+				return errors.Is(err, ErrNotFound) && errors.Is(err, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "unique violation",
+			err:  &pq.Error{Code: "23505", Constraint: "users_email_key", Detail: `Key (email)=(a@b.c) already exists.`},
+			check: func(err error) bool {
+				var e *ErrUniqueViolation
+				return errors.As(err, &e) && e.Constraint == "users_email_key" && reflect.DeepEqual(e.Columns, []string{"email"})
+			},
+		},
+		{
+			name: "foreign key violation",
+			err:  &pq.Error{Code: "23503", Constraint: "orders_user_fk", Detail: `Key (tenant_id, user_id)=(1, 5) is not present in table "users".`},
+			check: func(err error) bool {
+				var e *ErrForeignKeyViolation
+				return errors.As(err, &e) && reflect.DeepEqual(e.Columns, []string{"tenant_id", "user_id"})
+			},
+		},
+		{
+			name: "check violation",
+			err:  &pq.Error{Code: "23514", Constraint: "positive_amount"},
+			check: func(err error) bool {
+				var e *ErrCheckViolation
+				return errors.As(err, &e) && e.Constraint == "positive_amount"
+			},
+		},
+		{
+			name: "deadlock",
+			err:  &pq.Error{Code: "40P01"},
+			check: func(err error) bool {
+				var e *ErrSerializationFailure
+				var pqErr *pq.Error
+				return errors.As(err, &e) && errors.As(err, &pqErr)
+			},
+		},
+		{
+			name: "other error",
+			err:  &pq.Error{Code: "42P01"},
+			check: func(err error) bool {
+				var pqErr *pq.Error
+				return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := classifyError(tt.err); !tt.check(err) {
+				t.Errorf("unexpected classified error: %v", err)
+			}
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_NotFound(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"created", "updated", "id", "name"}))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	if _, err = rel.Find(context.Background(), 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+	if err = rel.Delete(context.Background(), 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// BeforeInserter is implemented by entities to be notified before Insert, InsertMany, Upsert and CopyFrom.
+// An error aborts the operation.
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInserter is implemented by entities to be notified after Insert, InsertMany and Upsert.
+// The entity is already inserted when the error is returned, use a transaction to roll it back.
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdater is implemented by entities to be notified before Update, UpdateFields and UpdateChanges.
+// An error aborts the operation.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdater is implemented by entities to be notified after Update, UpdateFields and UpdateChanges.
+// The entity is already updated when the error is returned, use a transaction to roll it back.
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// AfterFinder is implemented by entities to be notified after they are found by Find, FindBy, FindOneBy,
+// FindPage, FindKeyset, Iter and IterPtr. An error is returned instead of the entities.
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+// BeforeDeleter is implemented by entities to be notified before Delete and ForceDelete.
+// The hook is called on an entity with only the primary key fields set. An error aborts the operation.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+func beforeInsert[T any](ctx context.Context, entity *T) error {
+	if h, ok := any(entity).(BeforeInserter); ok {
+		if err := h.BeforeInsert(ctx); err != nil {
+			return fmt.Errorf("before insert: %w", err)
+		}
+	}
+	return nil
+}
+
+func afterInsert[T any](ctx context.Context, entity *T) error {
+	if h, ok := any(entity).(AfterInserter); ok {
+		if err := h.AfterInsert(ctx); err != nil {
+			return fmt.Errorf("after insert: %w", err)
+		}
+	}
+	return nil
+}
+
+func beforeUpdate[T any](ctx context.Context, entity *T) error {
+	if h, ok := any(entity).(BeforeUpdater); ok {
+		if err := h.BeforeUpdate(ctx); err != nil {
+			return fmt.Errorf("before update: %w", err)
+		}
+	}
+	return nil
+}
+
+func afterUpdate[T any](ctx context.Context, entity *T) error {
+	if h, ok := any(entity).(AfterUpdater); ok {
+		if err := h.AfterUpdate(ctx); err != nil {
+			return fmt.Errorf("after update: %w", err)
+		}
+	}
+	return nil
+}
+
+func afterFind[T any](ctx context.Context, entity *T) error {
+	if h, ok := any(entity).(AfterFinder); ok {
+		if err := h.AfterFind(ctx); err != nil {
+			return fmt.Errorf("after find: %w", err)
+		}
+	}
+	return nil
+}
+
+// beforeDelete calls the BeforeDelete hook on an entity built from the primary key values.
+func (r *Relation[T]) beforeDelete(ctx context.Context, id []any) error {
+	var entity T
+	h, ok := any(&entity).(BeforeDeleter)
+	if !ok {
+		return nil
+	}
+	v := reflect.ValueOf(&entity).Elem()
+	for i, col := range r.M.PKColumns() {
+		f := v
+		for _, index := range col.path {
+			f = f.Field(index)
+		}
+		idv, ok := convertID(reflect.ValueOf(id[i]), f.Type())
+		if !ok {
+			return fmt.Errorf("before delete: can not set %s to %T", col.name, id[i])
+		}
+		f.Set(idv)
+	}
+	if err := h.BeforeDelete(ctx); err != nil {
+		return fmt.Errorf("before delete: %w", err)
+	}
+	return nil
+}
+
+// convertID converts the id value to the type of the primary key field.
+// Only assignable values, same kind conversions and lossless integer conversions are allowed,
+// so e.g. an int id is never converted to a string.
+func convertID(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return v, false
+	}
+	if v.Type().AssignableTo(t) {
+		return v, true
+	}
+	zero := reflect.Zero(t)
+	switch {
+	case isIntKind(v.Kind()) && isIntKind(t.Kind()):
+		if zero.OverflowInt(v.Int()) {
+			return v, false
+		}
+	case isIntKind(v.Kind()) && isUintKind(t.Kind()):
+		if v.Int() < 0 || zero.OverflowUint(uint64(v.Int())) {
+			return v, false
+		}
+	case isUintKind(v.Kind()) && isUintKind(t.Kind()):
+		if zero.OverflowUint(v.Uint()) {
+			return v, false
+		}
+	case isUintKind(v.Kind()) && isIntKind(t.Kind()):
+		if v.Uint() > math.MaxInt64 || zero.OverflowInt(int64(v.Uint())) {
+			return v, false
+		}
+	case v.Kind() != t.Kind() || !v.Type().ConvertibleTo(t):
+		return v, false
+	}
+	return v.Convert(t), true
+}
+
+func isIntKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUintKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
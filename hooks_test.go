@@ -0,0 +1,177 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+var errHookInvalid = errors.New("invalid entity")
+
+type entityHooked struct {
+	ID      int64  `db:"id"`
+	Name    string `db:"name"`
+	Display string
+	Calls   []string
+}
+
+func (e *entityHooked) BeforeInsert(context.Context) error {
+	e.Calls = append(e.Calls, "before insert")
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return errHookInvalid
+	}
+	return nil
+}
+
+func (e *entityHooked) AfterInsert(context.Context) error {
+	e.Calls = append(e.Calls, "after insert")
+	return nil
+}
+
+func (e *entityHooked) BeforeUpdate(context.Context) error {
+	e.Calls = append(e.Calls, "before update")
+	return nil
+}
+
+func (e *entityHooked) AfterUpdate(context.Context) error {
+	e.Calls = append(e.Calls, "after update")
+	return nil
+}
+
+func (e *entityHooked) AfterFind(context.Context) error {
+	e.Display = strings.ToUpper(e.Name)
+	return nil
+}
+
+func (e *entityHooked) BeforeDelete(context.Context) error {
+	if e.ID == 1 {
+		return errHookInvalid
+	}
+	return nil
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_Hooks(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("name") VALUES ($1) RETURNING "id", "name"`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Test Name"))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1 WHERE "id" = $2 RETURNING "id", "name"`)).
+		WithArgs("Test Name", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Test Name"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "entities" WHERE "id" = $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Test Name"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rel, err := NewRelation[entityHooked]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	if err = rel.Insert(ctx, &entityHooked{Name: " "}); !errors.Is(err, errHookInvalid) {
+		t.Fatalf("expected insert to be aborted, got: %v", err)
+	}
+	e := &entityHooked{Name: " Test Name "}
+	if err = rel.Insert(ctx, e); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if err = rel.Update(ctx, e); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	expected := []string{"before insert", "after insert", "before update", "after update"}
+	if strings.Join(e.Calls, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected hook calls: %v", e.Calls)
+	}
+
+	found, err := rel.Find(ctx, 2)
+	if err != nil {
+		t.Fatalf("failed to find: %v", err)
+	}
+	if found.Display != "TEST NAME" {
+		t.Fatalf("expected after find hook to be called: %v", found)
+	}
+
+	if err = rel.Delete(ctx, 1); !errors.Is(err, errHookInvalid) {
+		t.Fatalf("expected delete to be aborted, got: %v", err)
+	}
+	if err = rel.Delete(ctx, 2); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_UpsertHooks(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("name") VALUES ($1) ON CONFLICT ("name") DO NOTHING RETURNING "id", "name"`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Test Name"))
+
+	rel, err := NewRelation[entityHooked]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	opts := UpsertOptions{ConflictColumns: []string{"name"}}
+
+	if err = rel.Upsert(context.Background(), &entityHooked{Name: " "}, opts); !errors.Is(err, errHookInvalid) {
+		t.Fatalf("expected upsert to be aborted, got: %v", err)
+	}
+	e := &entityHooked{Name: " Test Name "}
+	if err = rel.Upsert(context.Background(), e, opts); err != nil {
+		t.Fatalf("failed to upsert: %v", err)
+	}
+	if strings.Join(e.Calls, ",") != "before insert,after insert" {
+		t.Fatalf("unexpected hook calls: %v", e.Calls)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestConvertID(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       any
+		to       reflect.Type
+		expected bool
+	}{
+		{name: "assignable", id: int64(1), to: reflect.TypeOf(int64(0)), expected: true},
+		{name: "int to int64", id: 1, to: reflect.TypeOf(int64(0)), expected: true},
+		{name: "int to uint32", id: 1, to: reflect.TypeOf(uint32(0)), expected: true},
+		{name: "negative int to uint", id: -1, to: reflect.TypeOf(uint(0)), expected: false},
+		{name: "int overflows int8", id: 300, to: reflect.TypeOf(int8(0)), expected: false},
+		{name: "int to string", id: 65, to: reflect.TypeOf(""), expected: false},
+		{name: "string to named string", id: "a", to: reflect.TypeOf(Identifier("")), expected: true},
+		{name: "nil", id: nil, to: reflect.TypeOf(int64(0)), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := convertID(reflect.ValueOf(tt.id), tt.to)
+			if ok != tt.expected {
+				t.Fatalf("Expected %v, got %v", tt.expected, ok)
+			}
+			if ok && v.Type() != tt.to {
+				t.Errorf("Expected %s, got %s", tt.to, v.Type())
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// Iter lazily iterates over entities found by given condition.
+// Rows are scanned one by one and closed when the loop ends or breaks.
+// Iterated entities are not tracked.
+func (r *Relation[T]) Iter(ctx context.Context, cond Cond, sort Sort) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for e, err := range r.IterPtr(ctx, cond, sort) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(*e, nil) {
+				return
+			}
+		}
+	}
+}
+
+// IterPtr lazily iterates over entities found by given condition scanning every row into the same entity.
+// The pointer is reused between iterations, copy the entity to retain it.
+func (r *Relation[T]) IterPtr(ctx context.Context, cond Cond, sort Sort) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		query, args := r.buildFindBy(cond, sort, Pagination{})
+		rows, err := r.reader(ctx, "iter").QueryContext(ctx, query.ToSQL(), args...)
+		if err != nil {
+			yield(nil, fmt.Errorf("db iter query: %w", classifyError(err)))
+			return
+		}
+		defer rows.Close()
+
+		entity := new(T)
+		for rows.Next() {
+			var zero T
+			*entity = zero
+			if err := scanRow(rows.Scan, r.M, entity); err != nil {
+				yield(nil, fmt.Errorf("db iter scan: %w", err))
+				return
+			}
+			if err := afterFind(ctx, entity); err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(entity, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("db iter rows: %w", classifyError(err)))
+		}
+	}
+}
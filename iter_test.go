@@ -0,0 +1,84 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_Iter(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"created", "updated", "id", "name"}).
+		AddRow(now, now, 1, "First").
+		AddRow(now, now, 2, "Second").
+		AddRow(now, now, 3, "Third")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "created" < $1 ORDER BY id ASC`)).
+		WithArgs(now).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	var ids []int64
+	for ent, err := range rel.Iter(context.Background(), Cond{Lt("created", now)}, Sort{{"id", OrderAsc}}) {
+		if err != nil {
+			t.Fatalf("failed to iterate: %v", err)
+		}
+		ids = append(ids, ent.ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_IterPtr(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"created", "updated", "id", "name"}).
+		AddRow(now, now, 1, "First").
+		AddRow(now, now, 2, "Second")
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities"`)).
+		WillReturnRows(rows)
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	var prev *entitySerialID
+	var n int
+	for ent, err := range rel.IterPtr(context.Background(), nil, nil) {
+		if err != nil {
+			t.Fatalf("failed to iterate: %v", err)
+		}
+		if prev != nil && prev != ent {
+			t.Fatalf("expected the pointer to be reused")
+		}
+		prev = ent
+		n++
+	}
+	if n != 2 || prev.ID != 2 {
+		t.Fatalf("unexpected iteration result: %d, %v", n, prev)
+	}
+}
@@ -0,0 +1,225 @@
+package rel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ErrInvalidCursor is returned when a cursor token is malformed, tampered or issued for another sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Keyset is a keyset (cursor) paginator.
+// Pages are selected with comparisons against the sort key values of the last row of the previous page,
+// which stays fast on large tables unlike LIMIT/OFFSET. Sort columns must not contain NULLs.
+type Keyset struct {
+	sort   Sort
+	secret []byte
+}
+
+// cursorPayload is the signed content of a cursor token.
+type cursorPayload struct {
+	Columns []string `json:"c"`
+	Orders  []string `json:"o"`
+	Values  []any    `json:"v"`
+}
+
+// NewKeyset creates a new keyset paginator for the given sort.
+// Cursor tokens are signed with the secret to detect tampering.
+func NewKeyset(sort Sort, secret []byte) *Keyset {
+	return &Keyset{sort: sort, secret: secret}
+}
+
+// EncodeCursor returns an opaque signed cursor token for the given sort and values of its columns.
+func (k *Keyset) EncodeCursor(sort Sort, values []any) (string, error) {
+	columns, orders := splitSort(sort)
+	payload, err := json.Marshal(cursorPayload{Columns: columns, Orders: orders, Values: values})
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(k.sign(payload)), nil
+}
+
+// DecodeCursor verifies the cursor token issued for the same sort columns and orders
+// and returns sort key values encoded in it.
+func (k *Keyset) DecodeCursor(sort Sort, token string) ([]any, error) {
+	p, s, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || !hmac.Equal(sig, k.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursorPayload
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	// keep numbers as strings to avoid precision loss of big integers
+	dec.UseNumber()
+	if err = dec.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	columns, orders := splitSort(sort)
+	if !slices.Equal(c.Columns, columns) || !slices.Equal(c.Orders, orders) || len(c.Values) != len(columns) {
+		return nil, ErrInvalidCursor
+	}
+	return c.Values, nil
+}
+
+// splitSort returns columns and upper-case orders of the sort, an empty order is ascending.
+func splitSort(sort Sort) (columns, orders []string) {
+	columns = make([]string, len(sort))
+	orders = make([]string, len(sort))
+	for i, s := range sort {
+		columns[i] = s.Column
+		orders[i] = strings.ToUpper(s.Order)
+		if orders[i] == "" {
+			orders[i] = OrderAsc
+		}
+	}
+	return columns, orders
+}
+
+// sign returns HMAC-SHA256 of the payload.
+func (k *Keyset) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// FindKeyset finds a page of entities after the given cursor, an empty cursor selects the first page.
+// Primary key columns are appended to the sort as a tiebreaker.
+// The limit is capped by PaginationDefaultMaxLimit.
+// Returns the cursor of the next page, which is empty if there are no more entities.
+func (r *Relation[T]) FindKeyset(ctx context.Context, cond Cond, k *Keyset, cursor string, limit uint32) ([]T, string, error) {
+	if limit < 1 {
+		return nil, "", fmt.Errorf("keyset limit must be positive")
+	}
+	limit = Pagination{Limit: limit}.ComputeLimit()
+	sort, err := r.keysetSort(k.sort)
+	if err != nil {
+		return nil, "", err
+	}
+	columns := make([]string, len(sort))
+	identifiers := make(Sort, len(sort))
+	for i, s := range sort {
+		columns[i] = s.Column
+		identifiers[i] = ColumnOrder{Column: pq.QuoteIdentifier(s.Column), Order: s.Order}
+	}
+
+	// one extra entity is fetched to detect the next page
+	query, args := r.buildFindBy(cond, identifiers, Pagination{})
+	query.Limit(limit + 1)
+	if cursor != "" {
+		values, err := k.DecodeCursor(sort, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query.AndWhere(keysetExpr(identifiers, values, &args))
+	}
+
+	rows, err := r.reader(ctx, "find_keyset").QueryContext(ctx, query.ToSQL(), args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("db find keyset query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		var entity T
+		if err := scanRow(rows.Scan, r.M, &entity); err != nil {
+			return nil, "", fmt.Errorf("db find keyset scan: %w", err)
+		}
+		r.autoTrack(ctx, &entity)
+		if err := afterFind(ctx, &entity); err != nil {
+			return nil, "", err
+		}
+		items = append(items, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("db find keyset rows: %w", classifyError(err))
+	}
+
+	if len(items) <= int(limit) {
+		return items, "", nil
+	}
+	items = items[:limit]
+	next, err := k.EncodeCursor(sort, getFieldsValues(columns, r.M, &items[limit-1]))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return items, next, nil
+}
+
+// keysetSort validates the sort and appends missing primary key columns as a tiebreaker.
+func (r *Relation[T]) keysetSort(sort Sort) (Sort, error) {
+	res := make(Sort, 0, len(sort)+len(r.M.PKColumns()))
+	for _, s := range sort {
+		if _, ok := r.M.Column(s.Column); !ok {
+			return nil, fmt.Errorf("unknown sort column: %s", s.Column)
+		}
+		order := strings.ToUpper(s.Order)
+		if order != OrderAsc && order != OrderDesc {
+			return nil, fmt.Errorf("invalid sort order: %s", s.Order)
+		}
+		res = append(res, ColumnOrder{Column: s.Column, Order: order})
+	}
+	for _, col := range r.M.PKColumns() {
+		if !slices.ContainsFunc(res, func(s ColumnOrder) bool { return s.Column == col.name }) {
+			res = append(res, ColumnOrder{Column: col.name, Order: OrderAsc})
+		}
+	}
+	return res, nil
+}
+
+// keysetExpr returns a condition selecting rows after the given sort key values.
+// A row value comparison is used if all columns have the same order,
+// otherwise the comparison is expanded: (a > $1) OR (a = $1 AND b < $2) ...
+func keysetExpr(sort Sort, values []any, args *[]any) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = ArgsAdd(args, v)
+	}
+	cmp := func(order string) string {
+		if order == OrderDesc {
+			return " < "
+		}
+		return " > "
+	}
+
+	if !slices.ContainsFunc(sort, func(s ColumnOrder) bool { return s.Order != sort[0].Order }) {
+		columns := make([]string, len(sort))
+		for i, s := range sort {
+			columns[i] = s.Column
+		}
+		if len(sort) == 1 {
+			return columns[0] + cmp(sort[0].Order) + placeholders[0]
+		}
+		return "(" + strings.Join(columns, ", ") + ")" + cmp(sort[0].Order) + "(" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	var or []string
+	for i, s := range sort {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, sort[j].Column+" = "+placeholders[j])
+		}
+		and = append(and, s.Column+cmp(s.Order)+placeholders[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return "(" + strings.Join(or, " OR ") + ")"
+}
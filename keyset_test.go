@@ -0,0 +1,169 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestKeysetExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     Sort
+		expected string
+	}{
+		{
+			name:     "single column",
+			sort:     Sort{{`"id"`, OrderDesc}},
+			expected: `"id" < $1`,
+		},
+		{
+			name:     "same order",
+			sort:     Sort{{`"name"`, OrderAsc}, {`"id"`, OrderAsc}},
+			expected: `("name", "id") > ($1, $2)`,
+		},
+		{
+			name:     "mixed order",
+			sort:     Sort{{`"created"`, OrderDesc}, {`"id"`, OrderAsc}},
+			expected: `(("created" < $1) OR ("created" = $1 AND "id" > $2))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []any
+			values := make([]any, len(tt.sort))
+			if got := keysetExpr(tt.sort, values, &args); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+			if len(args) != len(tt.sort) {
+				t.Errorf("unexpected args: %v", args)
+			}
+		})
+	}
+}
+
+func TestKeyset_Cursor(t *testing.T) {
+	k := NewKeyset(nil, []byte("secret"))
+	sort := Sort{{"name", OrderAsc}, {"id", OrderAsc}}
+
+	token, err := k.EncodeCursor(sort, []any{"Test Name", int64(9007199254740993)})
+	if err != nil {
+		t.Fatalf("failed to encode cursor: %v", err)
+	}
+	values, err := k.DecodeCursor(sort, token)
+	if err != nil {
+		t.Fatalf("failed to decode cursor: %v", err)
+	}
+	if values[0] != "Test Name" || values[1].(interface{ String() string }).String() != "9007199254740993" {
+		t.Fatalf("unexpected values: %v", values)
+	}
+
+	if _, err = NewKeyset(nil, []byte("other")).DecodeCursor(sort, token); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor for another secret, got: %v", err)
+	}
+	if _, err = k.DecodeCursor(Sort{{"id", OrderAsc}}, token); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor for another sort, got: %v", err)
+	}
+	if _, err = k.DecodeCursor(Sort{{"name", OrderDesc}, {"id", OrderAsc}}, token); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor for another sort order, got: %v", err)
+	}
+	if _, err = k.DecodeCursor(Sort{{"name", "asc"}, {"id", ""}}, token); err != nil {
+		t.Fatalf("expected cursor for the same sort to be valid, got: %v", err)
+	}
+	if _, err = k.DecodeCursor(sort, "x"+token); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor for tampered token, got: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_FindKeyset(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"created", "updated", "id", "name"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "name" = $1 ORDER BY "created" DESC, "id" ASC LIMIT 3`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(created, created, 1, "Test Name").
+			AddRow(created, created, 2, "Test Name").
+			AddRow(created, created, 3, "Test Name"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "name" = $1 AND (("created" < $2) OR ("created" = $2 AND "id" > $3)) ORDER BY "created" DESC, "id" ASC LIMIT 3`)).
+		WithArgs("Test Name", created.Format(time.RFC3339Nano), "2").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(created, created, 3, "Test Name"))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	k := NewKeyset(Sort{{"created", OrderDesc}}, []byte("secret"))
+	cond := Cond{Eq("name", "Test Name")}
+
+	items, next, err := rel.FindKeyset(context.Background(), cond, k, "", 2)
+	if err != nil {
+		t.Fatalf("failed to find first page: %v", err)
+	}
+	if len(items) != 2 || next == "" {
+		t.Fatalf("unexpected first page: %v, %q", items, next)
+	}
+
+	items, next, err = rel.FindKeyset(context.Background(), cond, k, next, 2)
+	if err != nil {
+		t.Fatalf("failed to find second page: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != 3 || next != "" {
+		t.Fatalf("unexpected second page: %v, %q", items, next)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_FindKeysetMaxLimit(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	maxLimit := PaginationDefaultMaxLimit
+	PaginationDefaultMaxLimit = 2
+	t.Cleanup(func() { PaginationDefaultMaxLimit = maxLimit })
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"created", "updated", "id", "name"}
+	for range 2 {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" ORDER BY "id" ASC LIMIT 3`)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(created, created, 1, "Test Name").
+				AddRow(created, created, 2, "Test Name").
+				AddRow(created, created, 3, "Test Name"))
+	}
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	k := NewKeyset(nil, []byte("secret"))
+
+	// limits at and above the max fetch one extra entity to detect the next page
+	for _, limit := range []uint32{2, 5} {
+		items, next, err := rel.FindKeyset(context.Background(), nil, k, "", limit)
+		if err != nil {
+			t.Fatalf("failed to find page with limit %d: %v", limit, err)
+		}
+		if len(items) != 2 || next == "" {
+			t.Fatalf("unexpected page with limit %d: %v, %q", limit, items, next)
+		}
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
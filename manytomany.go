@@ -0,0 +1,182 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/slmder/rel/qbuilder"
+)
+
+// joinAlias is the alias of the join table in preload queries.
+const joinAlias = `"j"`
+
+// JoinTable is a many-to-many association of E entities with R entities of the related relation
+// through a join table referencing both primary keys.
+type JoinTable[E, R any] struct {
+	related   *Relation[R]
+	table     string
+	fk        string
+	relatedFK string
+	field     string
+}
+
+// ManyToMany declares that E entities are associated with many R entities of the related relation
+// through the join table, where fk references the E primary key and relatedFK the R primary key.
+// The pair (fk, relatedFK) must be unique. Loaded entities are stored into the field of type []R or []*R.
+func ManyToMany[E, R any](related *Relation[R], table, fk, relatedFK string) *JoinTable[E, R] {
+	return &JoinTable[E, R]{related: related, table: table, fk: fk, relatedFK: relatedFK}
+}
+
+// As returns a copy of the association that stores loaded entities into the named field.
+// By default the field is detected by its type.
+func (j *JoinTable[E, R]) As(field string) *JoinTable[E, R] {
+	cp := *j
+	cp.field = field
+	return &cp
+}
+
+// Attach associates the entity with the given related ids, existing associations are kept.
+func (j *JoinTable[E, R]) Attach(ctx context.Context, id any, relatedIDs ...any) error {
+	if len(relatedIDs) == 0 {
+		return nil
+	}
+	if _, err := j.related.hooked(ctx, "attach").ExecContext(ctx, j.buildAttachQuery(len(relatedIDs)), append([]any{id}, relatedIDs...)...); err != nil {
+		return fmt.Errorf("attach: %w", classifyError(err))
+	}
+	return nil
+}
+
+// Detach removes associations of the entity with the given related ids.
+func (j *JoinTable[E, R]) Detach(ctx context.Context, id any, relatedIDs ...any) error {
+	if len(relatedIDs) == 0 {
+		return nil
+	}
+	qb := qbuilder.Delete(quoteName(j.table))
+	qb.AndWhere(pq.QuoteIdentifier(j.fk) + " = $1")
+	qb.AndWhere(pq.QuoteIdentifier(j.relatedFK) + " = ANY($2)")
+
+	if _, err := j.related.hooked(ctx, "detach").ExecContext(ctx, qb.ToSQL(), id, pq.Array(relatedIDs)); err != nil {
+		return fmt.Errorf("detach: %w", classifyError(err))
+	}
+	return nil
+}
+
+// Sync makes the given related ids the only associations of the entity, no ids remove all associations.
+// Runs in the active transaction if present, otherwise in a new one.
+func (j *JoinTable[E, R]) Sync(ctx context.Context, id any, relatedIDs ...any) (err error) {
+	q := j.related.querier(ctx)
+	if db, ok := q.(*sql.DB); ok {
+		tx, bErr := db.BeginTx(ctx, nil)
+		if bErr != nil {
+			return fmt.Errorf("sync begin tx: %w", bErr)
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+				return
+			}
+			if cErr := tx.Commit(); cErr != nil {
+				err = fmt.Errorf("sync commit tx: %w", classifyError(cErr))
+			}
+		}()
+		q = tx
+	}
+	q = j.related.withHooks(q, "sync")
+
+	qb := qbuilder.Delete(quoteName(j.table))
+	qb.AndWhere(pq.QuoteIdentifier(j.fk) + " = $1")
+	args := []any{id}
+	// pq.Array binds an empty slice as NULL, which would match no rows
+	if len(relatedIDs) > 0 {
+		qb.AndWhere("NOT (" + pq.QuoteIdentifier(j.relatedFK) + " = ANY($2))")
+		args = append(args, pq.Array(relatedIDs))
+	}
+
+	if _, err = q.ExecContext(ctx, qb.ToSQL(), args...); err != nil {
+		return fmt.Errorf("sync detach: %w", classifyError(err))
+	}
+	if len(relatedIDs) == 0 {
+		return nil
+	}
+	if _, err = q.ExecContext(ctx, j.buildAttachQuery(len(relatedIDs)), append([]any{id}, relatedIDs...)...); err != nil {
+		return fmt.Errorf("sync attach: %w", classifyError(err))
+	}
+	return nil
+}
+
+// buildAttachQuery builds a query to insert n associations skipping existing ones.
+func (j *JoinTable[E, R]) buildAttachQuery(n int) string {
+	fk, relatedFK := pq.QuoteIdentifier(j.fk), pq.QuoteIdentifier(j.relatedFK)
+	qb := qbuilder.Insert(quoteName(j.table))
+	qb.Columns(fk, relatedFK)
+	values := make([][]string, n)
+	for i := range values {
+		values[i] = []string{"$1", "$" + strconv.Itoa(i+2)}
+	}
+	qb.Values(values...)
+	qb.OnConflict(fk+", "+relatedFK, false).DoNothing()
+
+	return qb.ToSQL()
+}
+
+// load loads related entities of the entities with a single query joining the join table.
+func (j *JoinTable[E, R]) load(ctx context.Context, r *Relation[E], entities []*E) error {
+	if len(r.M.PKColumns()) != 1 || len(j.related.M.PKColumns()) != 1 {
+		return fmt.Errorf("preload: many to many requires single column primary keys")
+	}
+	field, elemPtr, err := assocField[E, R](j.field, true)
+	if err != nil {
+		return err
+	}
+
+	pk := r.M.PKColumns()[0].name
+	keys := assocKeys(entities, r.M, pk)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	rel := j.related
+	columns := make([]string, 0, len(rel.M.Columns())+1)
+	for _, col := range rel.M.Columns() {
+		columns = append(columns, rel.name+"."+col.Identifier())
+	}
+	fk := joinAlias + "." + pq.QuoteIdentifier(j.fk)
+	// the join table may have columns named like the soft delete column, so the scope is qualified
+	query := buildFindByQuery(rel.name, rel.M, rel.scopeExpr(rel.name)...)
+	query.Select(append(columns, fk)...)
+	query.InnerJoin(quoteName(j.table), joinAlias,
+		joinAlias+"."+pq.QuoteIdentifier(j.relatedFK)+" = "+rel.name+"."+rel.M.PKColumns()[0].Identifier())
+	query.AndWhere(fk + " = ANY($1)")
+
+	rows, err := rel.reader(ctx, "preload").QueryContext(ctx, query.ToSQL(), pq.Array(keys))
+	if err != nil {
+		return fmt.Errorf("db preload query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	groups := make(map[any][]*R)
+	for rows.Next() {
+		var (
+			entity R
+			key    any
+		)
+		scan := func(dest ...any) error {
+			return rows.Scan(append(dest, &key)...)
+		}
+		if err := scanRow(scan, rel.M, &entity); err != nil {
+			return fmt.Errorf("db preload scan: %w", err)
+		}
+		groups[assocKey(key)] = append(groups[assocKey(key)], &entity)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("db preload rows: %w", classifyError(err))
+	}
+
+	for _, e := range entities {
+		assignMany(e, field, elemPtr, groups[assocKey(getFieldsValues([]string{pk}, r.M, e)[0])])
+	}
+	return nil
+}
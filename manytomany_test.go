@@ -0,0 +1,145 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type entityMember struct {
+	ID    int64 `db:"id"`
+	Roles []entityRole
+}
+
+type entityRole struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestJoinTable_AttachDetachSync(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "member_roles" ("member_id", "role_id") VALUES ($1, $2), ($1, $3) ON CONFLICT ("member_id", "role_id") DO NOTHING`)).
+		WithArgs(1, 10, 11).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "member_roles" WHERE "member_id" = $1 AND "role_id" = ANY($2)`)).
+		WithArgs(1, "{10}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "member_roles" WHERE "member_id" = $1 AND NOT ("role_id" = ANY($2))`)).
+		WithArgs(1, "{11,12}").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "member_roles" ("member_id", "role_id") VALUES ($1, $2), ($1, $3) ON CONFLICT ("member_id", "role_id") DO NOTHING`)).
+		WithArgs(1, 11, 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "member_roles" WHERE "member_id" = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	roles, err := NewRelation[entityRole]("roles", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	memberRoles := ManyToMany[entityMember](roles, "member_roles", "member_id", "role_id")
+	ctx := context.Background()
+
+	if err = memberRoles.Attach(ctx, 1, 10, 11); err != nil {
+		t.Fatalf("failed to attach: %v", err)
+	}
+	if err = memberRoles.Detach(ctx, 1, 10); err != nil {
+		t.Fatalf("failed to detach: %v", err)
+	}
+	if err = memberRoles.Sync(ctx, 1, 11, 12); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if err = memberRoles.Sync(ctx, 1); err != nil {
+		t.Fatalf("failed to sync empty set: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestJoinTable_Preload(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "members"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "roles"."id", "roles"."name", "j"."member_id" FROM "roles" INNER JOIN "member_roles" AS "j" ON "j"."role_id" = "roles"."id" WHERE "j"."member_id" = ANY($1)`)).
+		WithArgs("{1,2}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "member_id"}).
+			AddRow(10, "admin", 1).
+			AddRow(11, "editor", 1).
+			AddRow(11, "editor", 2))
+
+	members, err := NewRelation[entityMember]("members", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	roles, err := NewRelation[entityRole]("roles", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	items, err := members.FindBy(context.Background(), nil, nil, Pagination{},
+		Preload(ManyToMany[entityMember](roles, "member_roles", "member_id", "role_id")))
+	if err != nil {
+		t.Fatalf("failed to find by: %v", err)
+	}
+	if len(items[0].Roles) != 2 || items[0].Roles[1].Name != "editor" {
+		t.Fatalf("unexpected roles of member 1: %v", items[0].Roles)
+	}
+	if len(items[1].Roles) != 1 || items[1].Roles[0].ID != 11 {
+		t.Fatalf("unexpected roles of member 2: %v", items[1].Roles)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestJoinTable_PreloadSoftDelete(t *testing.T) {
+	type entityMemberSoft struct {
+		ID    int64 `db:"id"`
+		Roles []*entitySoftDelete
+	}
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "roles"."id", "roles"."name", "roles"."deleted_at", "j"."member_id" FROM "roles" INNER JOIN "member_roles" AS "j" ON "j"."role_id" = "roles"."id" WHERE "roles"."deleted_at" IS NULL AND "j"."member_id" = ANY($1)`)).
+		WithArgs("{1}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "deleted_at", "member_id"}).
+			AddRow(10, "admin", nil, 1))
+
+	members, err := NewRelation[entityMemberSoft]("members", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	roles, err := NewRelation[entitySoftDelete]("roles", mockDB, SoftDelete[entitySoftDelete]("deleted_at"))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	member := entityMemberSoft{ID: 1}
+	if err = members.Load(context.Background(), ManyToMany[entityMemberSoft](roles, "member_roles", "member_id", "role_id"), &member); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if len(member.Roles) != 1 || member.Roles[0].Name != "admin" {
+		t.Fatalf("unexpected roles: %v", member.Roles)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
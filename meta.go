@@ -4,13 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/lib/pq"
 )
 
 const maxRecursionDepth = 10
 
+const (
+	// tagOptVersion marks an optimistic locking version column, e.g. `db:"version,version"`.
+	tagOptVersion = "version"
+	// tagOptAutoCreateTime marks a column set on insert, e.g. `db:"created_at,autoCreateTime"`.
+	tagOptAutoCreateTime = "autoCreateTime"
+	// tagOptAutoUpdateTime marks a column set on insert and update, e.g. `db:"updated_at,autoUpdateTime"`.
+	tagOptAutoUpdateTime = "autoUpdateTime"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
 var metaCache sync.Map
 
 // ColumnMeta represents a column metadata.
@@ -18,6 +34,12 @@ type ColumnMeta struct {
 	pk   bool
 	name string
 	path []int
+	// version marks an optimistic locking version column.
+	version bool
+	// autoCreateTime marks a column set to the current time on insert.
+	autoCreateTime bool
+	// autoUpdateTime marks a column set to the current time on insert and update.
+	autoUpdateTime bool
 }
 
 // Identifier returns a quoted column name.
@@ -67,6 +89,12 @@ type Metadata[T any] struct {
 	updateColumns ListColumnMeta
 	// Map of columns by name for quick access.
 	columnsMap map[string]*ColumnMeta
+	// Optimistic locking version column, nil if not specified.
+	versionColumn *ColumnMeta
+	// Columns set to the current time on insert.
+	autoCreateTimeColumns ListColumnMeta
+	// Columns set to the current time on insert and update.
+	autoUpdateTimeColumns ListColumnMeta
 }
 
 func (m Metadata[T]) PkStrategy() PKStrategy {
@@ -89,6 +117,41 @@ func (m Metadata[T]) UpdateColumns() ListColumnMeta {
 	return m.updateColumns
 }
 
+// AutoCreateTimeColumns returns columns set to the current time on insert.
+func (m Metadata[T]) AutoCreateTimeColumns() ListColumnMeta {
+	return m.autoCreateTimeColumns
+}
+
+// AutoUpdateTimeColumns returns columns set to the current time on insert and update.
+func (m Metadata[T]) AutoUpdateTimeColumns() ListColumnMeta {
+	return m.autoUpdateTimeColumns
+}
+
+// VersionColumn returns the optimistic locking version column or nil.
+func (m Metadata[T]) VersionColumn() *ColumnMeta {
+	return m.versionColumn
+}
+
+// Column returns a column metadata by the given name.
+func (m Metadata[T]) Column(name string) (*ColumnMeta, bool) {
+	cm, ok := m.columnsMap[name]
+	return cm, ok
+}
+
+// ColumnsByNames returns columns metadata by the given names in the same order.
+// Returns an error if any of the columns is unknown.
+func (m Metadata[T]) ColumnsByNames(names ...string) (ListColumnMeta, error) {
+	res := make(ListColumnMeta, len(names))
+	for i, name := range names {
+		cm, ok := m.columnsMap[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %s", name)
+		}
+		res[i] = cm
+	}
+	return res, nil
+}
+
 // NewMeta creates a new Metadata instance for the given T type.
 func NewMeta[T any](pkStrategy PKStrategy, pk ...string) (*Metadata[T], error) {
 	if len(pk) == 0 {
@@ -121,6 +184,14 @@ func NewMeta[T any](pkStrategy PKStrategy, pk ...string) (*Metadata[T], error) {
 	m.insertColumns = insertColumns(m.columns, pkStrategy)
 	m.updateColumns = updateColumns(m.columns)
 	m.columnsMap = columnsMetaMap(m.columns)
+	if versions := filter(m.columns, func(cm *ColumnMeta) bool { return cm.version }); len(versions) > 0 {
+		if len(versions) > 1 {
+			return nil, errors.New("more than one version column")
+		}
+		m.versionColumn = versions[0]
+	}
+	m.autoCreateTimeColumns = filter(m.columns, func(cm *ColumnMeta) bool { return cm.autoCreateTime })
+	m.autoUpdateTimeColumns = filter(m.columns, func(cm *ColumnMeta) bool { return cm.autoUpdateTime })
 
 	actual, loaded := metaCache.LoadOrStore(typeName, m)
 	if loaded {
@@ -164,21 +235,29 @@ func columnsMeta[T any](pk ...string) ([]*ColumnMeta, error) {
 			}
 
 			if tag, ok := field.Tag.Lookup("db"); ok {
-				if !isValidColumnName(tag) {
-					return fmt.Errorf("invalid column: %s", tag)
+				name, opts, _ := strings.Cut(tag, ",")
+				if !isValidColumnName(name) {
+					return fmt.Errorf("invalid column: %s", name)
 				}
 				isPk := false
 				for _, p := range pk {
-					if p == tag {
+					if p == name {
 						isPk = true
 						break
 					}
 				}
-				metas = append(metas, &ColumnMeta{
+				cm := &ColumnMeta{
 					pk:   isPk,
-					name: tag,
+					name: name,
 					path: fieldPath,
-				})
+				}
+				if err := applyTagOptions(cm, opts); err != nil {
+					return err
+				}
+				if (cm.autoCreateTime || cm.autoUpdateTime) && field.Type != timeType && field.Type != timePtrType {
+					return fmt.Errorf("column %s: auto time requires time.Time or *time.Time field", name)
+				}
+				metas = append(metas, cm)
 			}
 		}
 		return nil
@@ -187,6 +266,26 @@ func columnsMeta[T any](pk ...string) ([]*ColumnMeta, error) {
 	return metas, collectMeta(t, nil, 0)
 }
 
+// applyTagOptions applies comma separated db tag options to the column metadata.
+func applyTagOptions(cm *ColumnMeta, opts string) error {
+	if opts == "" {
+		return nil
+	}
+	for _, opt := range strings.Split(opts, ",") {
+		switch strings.TrimSpace(opt) {
+		case tagOptVersion:
+			cm.version = true
+		case tagOptAutoCreateTime:
+			cm.autoCreateTime = true
+		case tagOptAutoUpdateTime:
+			cm.autoUpdateTime = true
+		default:
+			return fmt.Errorf("invalid column %s option: %s", cm.name, opt)
+		}
+	}
+	return nil
+}
+
 // columnsMetaMap returns a map of column metadata by name.
 func columnsMetaMap(columns []*ColumnMeta) map[string]*ColumnMeta {
 	cmm := make(map[string]*ColumnMeta)
@@ -209,9 +308,10 @@ func insertColumns(columns []*ColumnMeta, pkStrategy PKStrategy) ListColumnMeta
 	})
 }
 
+// updateColumns returns columns for update excluding primary key, version and auto create time columns.
 func updateColumns(columns []*ColumnMeta) ListColumnMeta {
 	return filter(columns, func(cm *ColumnMeta) bool {
-		return !cm.pk
+		return !cm.pk && !cm.version && !cm.autoCreateTime
 	})
 }
 
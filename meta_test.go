@@ -70,6 +70,32 @@ func TestColumnsMeta(t *testing.T) {
 	}
 }
 
+// columnsMeta() tag options
+func TestColumnsMeta_TagOptions(t *testing.T) {
+	type Versioned struct {
+		ID      int `db:"id"`
+		Version int `db:"version,version"`
+	}
+	type Invalid struct {
+		ID int `db:"id,unknown"`
+	}
+
+	meta, err := NewMeta[Versioned](0, "id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v := meta.VersionColumn(); v == nil || v.name != "version" {
+		t.Errorf("Expected version column, got %v", v)
+	}
+	if got := meta.UpdateColumns().Names(); len(got) != 0 {
+		t.Errorf("Expected no update columns, got %v", got)
+	}
+
+	if _, err = columnsMeta[Invalid]("id"); err == nil {
+		t.Errorf("Expected error for unknown tag option")
+	}
+}
+
 // pkColumns()
 func TestPKColumns(t *testing.T) {
 	columns := ListColumnMeta{
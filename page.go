@@ -0,0 +1,75 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+)
+
+// Page represents a page of entities with pagination metadata.
+type Page[T any] struct {
+	Items      []T
+	Total      int64
+	Page       uint32
+	Limit      uint32
+	TotalPages uint32
+	HasNext    bool
+	HasPrev    bool
+}
+
+// FindPage finds a page of entities by given condition along with the total count.
+// The total is selected with COUNT(*) OVER() in the same query, CountBy is used only for pages past the end.
+// A zero limit is replaced with PaginationDefaultMaxLimit.
+func (r *Relation[T]) FindPage(ctx context.Context, cond Cond, sort Sort, pag Pagination) (Page[T], error) {
+	if pag.Limit < 1 {
+		pag.Limit = PaginationDefaultMaxLimit
+	}
+	if pag.Page < 1 {
+		pag.Page = 1
+	}
+	page := Page[T]{Page: pag.Page, Limit: pag.ComputeLimit()}
+
+	query, args := r.buildFindBy(cond, sort, pag)
+	query.AddSelect("COUNT(*) OVER()")
+
+	rows, err := r.reader(ctx, "find_page").QueryContext(ctx, query.ToSQL(), args...)
+	if err != nil {
+		return page, fmt.Errorf("db find page query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity T
+		pointers, err := getFieldsPointers(r.M.Columns().Names(), r.M, &entity)
+		if err != nil {
+			return page, fmt.Errorf("db find page scan: %w", err)
+		}
+		if err = rows.Scan(append(pointers, &page.Total)...); err != nil {
+			return page, fmt.Errorf("db find page scan: %w", err)
+		}
+		r.autoTrack(ctx, &entity)
+		if err := afterFind(ctx, &entity); err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, entity)
+	}
+	if err = rows.Err(); err != nil {
+		return page, fmt.Errorf("db find page rows: %w", classifyError(err))
+	}
+
+	if len(page.Items) == 0 && pag.Page > 1 {
+		if page.Total, err = r.CountBy(ctx, cond); err != nil {
+			return page, fmt.Errorf("db find page count: %w", err)
+		}
+	}
+
+	switch {
+	case page.Limit > 0:
+		page.TotalPages = uint32((page.Total + int64(page.Limit) - 1) / int64(page.Limit))
+	case page.Total > 0:
+		page.TotalPages = 1
+	}
+	page.HasNext = page.Page < page.TotalPages
+	page.HasPrev = page.Page > 1
+
+	return page, nil
+}
@@ -0,0 +1,87 @@
+package rel
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_FindPage(t *testing.T) {
+	now := time.Now()
+	columns := []string{"created", "updated", "id", "name", "count"}
+
+	tests := []struct {
+		name     string
+		pag      Pagination
+		expect   func(mock sqlmock.Sqlmock)
+		expected Page[entitySerialID]
+	}{
+		{
+			name: "middle page",
+			pag:  Pagination{Limit: 2, Page: 2},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name", COUNT(*) OVER() FROM "entities" WHERE "name" = $1 LIMIT 2 OFFSET 2`)).
+					WithArgs("Test Name").
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(now, now, 3, "Test Name", 5).
+						AddRow(now, now, 4, "Test Name", 5))
+			},
+			expected: Page[entitySerialID]{Total: 5, Page: 2, Limit: 2, TotalPages: 3, HasNext: true, HasPrev: true},
+		},
+		{
+			name: "past the end",
+			pag:  Pagination{Limit: 2, Page: 4},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name", COUNT(*) OVER() FROM "entities" WHERE "name" = $1 LIMIT 2 OFFSET 6`)).
+					WithArgs("Test Name").
+					WillReturnRows(sqlmock.NewRows(columns))
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM "entities" WHERE "name" = $1`)).
+					WithArgs("Test Name").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+			},
+			expected: Page[entitySerialID]{Total: 5, Page: 4, Limit: 2, TotalPages: 3, HasNext: false, HasPrev: true},
+		},
+		{
+			name: "limit capped",
+			pag:  Pagination{Limit: 1000},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name", COUNT(*) OVER() FROM "entities" WHERE "name" = $1 LIMIT 200`)).
+					WithArgs("Test Name").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow(now, now, 1, "Test Name", 1))
+			},
+			expected: Page[entitySerialID]{Total: 1, Page: 1, Limit: 200, TotalPages: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock db: %v", err)
+			}
+			tt.expect(mock)
+
+			rel, err := NewRelation[entitySerialID]("entities", mockDB)
+			if err != nil {
+				t.Fatalf("failed to create relation: %v", err)
+			}
+
+			page, err := rel.FindPage(context.Background(), Cond{Eq("name", "Test Name")}, nil, tt.pag)
+			if err != nil {
+				t.Fatalf("failed to find page: %v", err)
+			}
+			page.Items = nil
+			if !reflect.DeepEqual(page, tt.expected) {
+				t.Fatalf("unexpected page: %+v", page)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
@@ -20,14 +20,17 @@ func (p Pagination) ComputeOffset() uint32 {
 		return 0
 	}
 
-	return (p.Page - 1) * p.Limit
+	return (p.Page - 1) * p.ComputeLimit()
 }
 
-// ComputeLimit returns the limit
+// ComputeLimit returns the limit capped by PaginationDefaultMaxLimit
 func (p Pagination) ComputeLimit() uint32 {
 	if p.Limit < 1 {
 		return 0
 	}
+	if PaginationDefaultMaxLimit > 0 && p.Limit > PaginationDefaultMaxLimit {
+		return PaginationDefaultMaxLimit
+	}
 
 	return p.Limit
 }
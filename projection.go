@@ -0,0 +1,109 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/slmder/rel/qbuilder"
+)
+
+// projectionMetaCache caches metadata of projection types by reflect.Type.
+var projectionMetaCache sync.Map
+
+// findOptions represents options of find queries.
+type findOptions struct {
+	// columns to select, all columns if empty
+	columns []string
+	// associations to preload, Association of the relation entity type
+	preload []any
+}
+
+// FindOption is an option of find queries.
+type FindOption func(*findOptions)
+
+// Select selects only the given columns, other fields of found entities are left zero.
+// Entities found with a projection are not tracked.
+func Select(columns ...string) FindOption {
+	return func(o *findOptions) {
+		o.columns = columns
+	}
+}
+
+// newFindOptions applies the given options.
+func newFindOptions(opts []FindOption) findOptions {
+	var o findOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// project replaces the selected columns of the query according to the options and returns columns to scan.
+func (r *Relation[T]) project(query *qbuilder.SelectBuilder, o findOptions) ([]string, error) {
+	if len(o.columns) == 0 {
+		return r.M.Columns().Names(), nil
+	}
+	cols, err := r.M.ColumnsByNames(o.columns...)
+	if err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+	query.Select(cols.Identifiers()...)
+
+	return o.columns, nil
+}
+
+// FindAs finds entities of the relation by given condition and scans them into D.
+// Only columns tagged in D are selected, they must be a subset of the relation columns.
+func FindAs[D, T any](ctx context.Context, r *Relation[T], cond Cond, sort Sort, pag Pagination) ([]D, error) {
+	m, err := projectionMeta[D]()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.M.ColumnsByNames(m.Columns().Names()...); err != nil {
+		return nil, fmt.Errorf("project %s: %w", reflect.TypeFor[D](), err)
+	}
+	query, args := r.buildFindBy(cond, sort, pag)
+	query.Select(m.Columns().Identifiers()...)
+
+	rows, err := r.reader(ctx, "find_as").QueryContext(ctx, query.ToSQL(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("db find as query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var items []D
+	for rows.Next() {
+		var item D
+		if err := scanRow(rows.Scan, m, &item); err != nil {
+			return nil, fmt.Errorf("db find as scan: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db find as rows: %w", classifyError(err))
+	}
+
+	return items, nil
+}
+
+// projectionMeta returns metadata of a projection type, which does not require a primary key.
+func projectionMeta[D any]() (*Metadata[D], error) {
+	t := reflect.TypeFor[D]()
+	if cached, ok := projectionMetaCache.Load(t); ok {
+		return cached.(*Metadata[D]), nil
+	}
+
+	columns, err := columnsMeta[D]()
+	if err != nil {
+		return nil, fmt.Errorf("build %s columns meta: %w", t, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("type %s has no columns", t)
+	}
+	m := &Metadata[D]{columns: columns, columnsMap: columnsMetaMap(columns)}
+	actual, _ := projectionMetaCache.LoadOrStore(t, m)
+
+	return actual.(*Metadata[D]), nil
+}
@@ -0,0 +1,92 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type entityNameDTO struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_FindBySelect(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "entities" WHERE "name" = $1`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Test Name"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "name" FROM "entities" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Test Name"))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	ents, err := rel.FindBy(context.Background(), Cond{Eq("name", "Test Name")}, nil, Pagination{}, Select("id", "name"))
+	if err != nil {
+		t.Fatalf("failed to find by: %v", err)
+	}
+	if len(ents) != 1 || ents[0].ID != 1 || !ents[0].Created.IsZero() {
+		t.Fatalf("unexpected result: %v", ents)
+	}
+
+	ent, err := rel.FindOneBy(context.Background(), Cond{Eq("id", 1)}, Select("name"))
+	if err != nil {
+		t.Fatalf("failed to find one by: %v", err)
+	}
+	if ent.Name != "Test Name" {
+		t.Fatalf("unexpected result: %v", ent)
+	}
+
+	if _, err = rel.FindBy(context.Background(), nil, nil, Pagination{}, Select("email")); err == nil {
+		t.Fatalf("expected error for unknown column")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestFindAs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name" FROM "entities" WHERE "created" < $1 ORDER BY name ASC LIMIT 10`)).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Test Name"))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	items, err := FindAs[entityNameDTO](context.Background(), rel, Cond{Lt("created", now)}, Sort{{"name", OrderAsc}}, Pagination{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to find as: %v", err)
+	}
+	if len(items) != 1 || items[0] != (entityNameDTO{ID: 1, Name: "Test Name"}) {
+		t.Fatalf("unexpected result: %v", items)
+	}
+
+	type unknownDTO struct {
+		Email string `db:"email"`
+	}
+	if _, err = FindAs[unknownDTO](context.Background(), rel, nil, nil, Pagination{}); err == nil {
+		t.Fatalf("expected error for unknown column")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
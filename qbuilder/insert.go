@@ -1,6 +1,8 @@
 package qbuilder
 
 import (
+	"maps"
+	"slices"
 	"strings"
 )
 
@@ -16,6 +18,7 @@ type InsertBuilder struct {
 	conflictConstraint bool
 	conflictAction     string
 	conflictSet        map[string]string
+	conflictWhere      []string
 	returning          []string
 }
 
@@ -61,6 +64,11 @@ func (b *InsertBuilder) DoUpdate(kv map[string]string) *InsertBuilder {
 	return b
 }
 
+func (b *InsertBuilder) DoUpdateWhere(where ...string) *InsertBuilder {
+	b.conflictWhere = append(b.conflictWhere, where...)
+	return b
+}
+
 func (b *InsertBuilder) Returning(alias ...string) *InsertBuilder {
 	b.returning = alias
 	return b
@@ -115,15 +123,20 @@ func (b *InsertBuilder) ToSQL() string {
 		if len(b.conflictSet) > 0 {
 			out.WriteString(" SET ")
 			i := len(b.conflictSet) - 1
-			for c, v := range b.conflictSet {
+			// sort columns to render a deterministic query
+			for _, c := range slices.Sorted(maps.Keys(b.conflictSet)) {
 				out.WriteString(c)
 				out.WriteString(" = ")
-				out.WriteString(v)
+				out.WriteString(b.conflictSet[c])
 				if i != 0 {
 					out.WriteString(comma)
 				}
 				i--
 			}
+			if len(b.conflictWhere) > 0 {
+				out.WriteString(" WHERE ")
+				out.WriteString(strings.Join(b.conflictWhere, " AND "))
+			}
 		}
 	}
 	if len(b.returning) > 0 {
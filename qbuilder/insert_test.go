@@ -38,6 +38,23 @@ func TestInsertBuilder_ToSQL(t *testing.T) {
 				DoUpdate(map[string]string{"name": "'Updated'"}),
 			expected: "INSERT INTO users (id, name) VALUES (1, 'John') ON CONFLICT (id) DO UPDATE SET name = 'Updated'",
 		},
+		{
+			name: "INSERT with ON CONFLICT DO UPDATE multiple columns",
+			builder: Insert("users").Columns("id", "name", "email").
+				Values([]string{"1", "'John'", "'john@example.com'"}).
+				OnConflict("id", false).
+				DoUpdate(map[string]string{"name": "EXCLUDED.name", "email": "EXCLUDED.email"}),
+			expected: "INSERT INTO users (id, name, email) VALUES (1, 'John', 'john@example.com') ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email, name = EXCLUDED.name",
+		},
+		{
+			name: "INSERT with ON CONFLICT DO UPDATE WHERE",
+			builder: Insert("users").Columns("id", "name").
+				Values([]string{"1", "'John'"}).
+				OnConflict("id", false).
+				DoUpdate(map[string]string{"name": "EXCLUDED.name"}).
+				DoUpdateWhere("users.deleted_at IS NULL", "users.locked = false"),
+			expected: "INSERT INTO users (id, name) VALUES (1, 'John') ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name WHERE users.deleted_at IS NULL AND users.locked = false",
+		},
 		{
 			name: "INSERT with ON CONFLICT ON CONSTRAINT",
 			builder: Insert("users").Columns("id", "name").
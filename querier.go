@@ -0,0 +1,38 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier is a common interface for *sql.DB and *sql.Tx.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+)
+
+// WithTx returns a copy of the relation that executes all queries on the given transaction.
+// Prebuilt queries and metadata are shared with the original relation.
+func (r *Relation[T]) WithTx(tx *sql.Tx) *Relation[T] {
+	cp := *r
+	cp.q = tx
+	return &cp
+}
+
+// querier returns the querier to execute queries on.
+// An explicitly bound transaction wins over the one propagated through the context.
+func (r *Relation[T]) querier(ctx context.Context) Querier {
+	if r.q != nil {
+		return r.q
+	}
+	if tx, ok := txFromContext(ctx, r.DB); ok {
+		return tx
+	}
+	return r.DB
+}
@@ -0,0 +1,65 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelation_WithTx(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+
+	mock.ExpectBegin()
+	eq := mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("created", "updated", "name") VALUES ($1, $2, $3) RETURNING "created", "updated", "id", "name"`))
+	eq.WithArgs(now, now, "Test Name")
+	eq.WillReturnRows(
+		sqlmock.NewRows([]string{"created", "updated", "id", "name"}).
+			AddRow(now, now, 1, "Test Name"),
+	)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	tx, err := mockDB.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin tx: %v", err)
+	}
+	txRel := rel.WithTx(tx)
+	if txRel == rel {
+		t.Fatalf("expected a copy of the relation")
+	}
+	if txRel.M != rel.M || txRel.insertQ != rel.insertQ {
+		t.Fatalf("expected metadata and prebuilt queries to be shared")
+	}
+
+	ent := entitySerialID{TimeStamps: TimeStamps{Created: now, Updated: now}, Name: "Test Name"}
+	if err = txRel.Insert(context.Background(), &ent); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if ent.ID != 1 {
+		t.Fatalf("unexpected ID: %d", ent.ID)
+	}
+	if err = txRel.Delete(context.Background(), 2); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
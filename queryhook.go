@@ -0,0 +1,125 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QueryEvent describes a query executed by a Relation.
+type QueryEvent struct {
+	// Operation is the Relation operation executing the query, e.g. "insert" or "find_by".
+	Operation string
+	// Relation is the relation name.
+	Relation string
+	// SQL is the executed query.
+	SQL string
+	// Args are the query arguments.
+	Args []any
+	// Duration is the query execution time, set for AfterQuery.
+	// Rows of QueryContext are not read yet when the duration is measured.
+	Duration time.Duration
+	// RowsAffected is the number of rows affected by ExecContext queries or copied by CopyFrom, set for AfterQuery.
+	RowsAffected int64
+	// Err is the query error, set for AfterQuery.
+	Err error
+}
+
+// QueryHook is notified around every query executed by a Relation.
+// BeforeQuery may return a derived context (e.g. with a tracing span) that is used to execute the query
+// and is passed to AfterQuery.
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, e QueryEvent) context.Context
+	AfterQuery(ctx context.Context, e QueryEvent)
+}
+
+// QueryHooks adds hooks notified around every query of the relation.
+// BeforeQuery hooks are called in the given order, AfterQuery hooks in the reverse order.
+func QueryHooks[T any](hooks ...QueryHook) Option[T] {
+	return func(r *Relation[T]) {
+		r.hooks = append(r.hooks, hooks...)
+	}
+}
+
+// hooked returns the querier for the operation notifying query hooks of the relation.
+func (r *Relation[T]) hooked(ctx context.Context, op string) Querier {
+	return r.withHooks(r.prepared(r.querier(ctx)), op)
+}
+
+// withHooks wraps the querier to notify query hooks of the relation, it is returned as is if there are no hooks.
+func (r *Relation[T]) withHooks(q Querier, op string) Querier {
+	if len(r.hooks) == 0 {
+		return q
+	}
+	return &hookedQuerier{q: q, hooks: r.hooks, op: op, rel: r.qualifiedTable()}
+}
+
+// observe notifies query hooks of the relation around an operation not executed by a Querier, e.g. COPY.
+// The returned function must be called with the result of the operation.
+func (r *Relation[T]) observe(ctx context.Context, op, query string) (context.Context, func(rowsAffected int64, err error)) {
+	if len(r.hooks) == 0 {
+		return ctx, func(int64, error) {}
+	}
+	h := &hookedQuerier{hooks: r.hooks, op: op, rel: r.qualifiedTable()}
+	ctx, e := h.before(ctx, query, nil)
+	start := time.Now()
+	return ctx, func(rowsAffected int64, err error) {
+		h.after(ctx, e, time.Since(start), rowsAffected, err)
+	}
+}
+
+// hookedQuerier is a Querier notifying query hooks.
+type hookedQuerier struct {
+	q     Querier
+	hooks []QueryHook
+	op    string
+	rel   string
+}
+
+func (h *hookedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, e := h.before(ctx, query, args)
+	start := time.Now()
+	rows, err := h.q.QueryContext(ctx, query, args...)
+	h.after(ctx, e, time.Since(start), 0, err)
+	return rows, err
+}
+
+func (h *hookedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, e := h.before(ctx, query, args)
+	start := time.Now()
+	row := h.q.QueryRowContext(ctx, query, args...)
+	h.after(ctx, e, time.Since(start), 0, row.Err())
+	return row
+}
+
+func (h *hookedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, e := h.before(ctx, query, args)
+	start := time.Now()
+	res, err := h.q.ExecContext(ctx, query, args...)
+	d := time.Since(start)
+	var n int64
+	if err == nil {
+		n, _ = res.RowsAffected()
+	}
+	h.after(ctx, e, d, n, err)
+	return res, err
+}
+
+// before notifies BeforeQuery hooks and returns the context to execute the query with.
+func (h *hookedQuerier) before(ctx context.Context, query string, args []any) (context.Context, QueryEvent) {
+	e := QueryEvent{Operation: h.op, Relation: h.rel, SQL: query, Args: args}
+	for _, hook := range h.hooks {
+		ctx = hook.BeforeQuery(ctx, e)
+	}
+	return ctx, e
+}
+
+// after notifies AfterQuery hooks in the reverse order.
+func (h *hookedQuerier) after(ctx context.Context, e QueryEvent, d time.Duration, rowsAffected int64, err error) {
+	e.Duration = d
+	e.RowsAffected = rowsAffected
+	e.Err = err
+	for i := len(h.hooks) - 1; i >= 0; i-- {
+		h.hooks[i].AfterQuery(ctx, e)
+	}
+}
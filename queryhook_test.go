@@ -0,0 +1,112 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type ctxKeyHook struct{}
+
+type recordingHook struct {
+	events []QueryEvent
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, _ QueryEvent) context.Context {
+	return context.WithValue(ctx, ctxKeyHook{}, true)
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, e QueryEvent) {
+	if ctx.Value(ctxKeyHook{}) == nil {
+		e.Err = errors.New("context of before query is lost")
+	}
+	h.events = append(h.events, e)
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_QueryHooks(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	insertQ := `INSERT INTO "entities" ("created", "updated", "name") VALUES ($1, $2, $3) RETURNING "created", "updated", "id", "name"`
+	deleteQ := `DELETE FROM "entities" WHERE "name" = $1`
+	mock.ExpectQuery(regexp.QuoteMeta(insertQ)).
+		WillReturnRows(sqlmock.NewRows([]string{"created", "updated", "id", "name"}))
+	mock.ExpectExec(regexp.QuoteMeta(deleteQ)).
+		WithArgs("Test Name").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(deleteQ)).
+		WithArgs("Test Name").
+		WillReturnError(errors.New("connection refused"))
+
+	hook := &recordingHook{}
+	rel, err := NewRelation[entitySerialID]("entities", mockDB, QueryHooks[entitySerialID](hook))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	_ = rel.Insert(ctx, &entitySerialID{Name: "Test Name"})
+	if _, err = rel.DeleteBy(ctx, Cond{Eq("name", "Test Name")}); err != nil {
+		t.Fatalf("failed to delete by: %v", err)
+	}
+	if _, err = rel.DeleteBy(ctx, Cond{Eq("name", "Test Name")}); err == nil {
+		t.Fatalf("expected delete by error")
+	}
+
+	if len(hook.events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(hook.events))
+	}
+	if e := hook.events[0]; e.Operation != "insert" || e.Relation != "entities" || e.SQL != insertQ || len(e.Args) != 3 || e.Err != nil {
+		t.Fatalf("unexpected insert event: %+v", e)
+	}
+	if e := hook.events[1]; e.Operation != "delete_by" || e.RowsAffected != 3 || e.Args[0] != "Test Name" || e.Err != nil {
+		t.Fatalf("unexpected delete by event: %+v", e)
+	}
+	if e := hook.events[2]; e.Err == nil || e.Err.Error() != "connection refused" {
+		t.Fatalf("unexpected failed delete by event: %+v", e)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelation_CopyFromQueryHooks(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	copyQ := `COPY "entities" ("created", "updated", "name") FROM STDIN`
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(regexp.QuoteMeta(copyQ))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithoutArgs().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	hook := &recordingHook{}
+	rel, err := NewRelation[entitySerialID]("entities", mockDB, QueryHooks[entitySerialID](hook))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	entities := []*entitySerialID{{Name: "First"}, {Name: "Second"}}
+	if _, err = rel.CopyFrom(context.Background(), slices.Values(entities)); err != nil {
+		t.Fatalf("failed to copy entities: %v", err)
+	}
+	if len(hook.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(hook.events))
+	}
+	if e := hook.events[0]; e.Operation != "copy_from" || e.SQL != copyQ || e.RowsAffected != 2 || e.Err != nil {
+		t.Fatalf("unexpected copy from event: %+v", e)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -3,10 +3,15 @@ package rel
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/slmder/rel/qbuilder"
@@ -14,15 +19,24 @@ import (
 
 const defaultPkColumn = "id"
 
+// maxBindParams is the maximum number of bind parameters postgres accepts in a single query.
+const maxBindParams = 65535
+
 // Relation is a database abstraction layer that provides basic CRUD operations for a given type.
 // Uses reflection prebuild queries for the given type and table
 type Relation[T any] struct {
 	*sql.DB
+	// q overrides the querier queries are executed on (e.g. a transaction)
+	q Querier
 	// metadata
 	M *Metadata[T]
 
 	// Relation name
 	name string
+	// schema is the unquoted schema name, the search path is used if empty
+	schema string
+	// table is the unquoted relation name
+	table string
 	// primary key columns
 	pk []string
 	// primary key strategy
@@ -36,6 +50,30 @@ type Relation[T any] struct {
 	updateQ string
 	// deleteQ is a prebuilt query to delete an entity
 	deleteQ string
+	// upsertQ is a prebuilt query to insert or update an entity on primary key conflict
+	upsertQ string
+	// queries caches queries built at runtime, shared between relation copies
+	queries *sync.Map
+	// tracking enables automatic snapshots of loaded entities
+	tracking bool
+	// softDeleteColumn is the soft delete timestamp column, soft delete is disabled if empty
+	softDeleteColumn string
+	// scope defines visibility of soft deleted entities
+	scope trashedScope
+	// softDeleteQ is a prebuilt query to soft delete an entity
+	softDeleteQ string
+	// restoreQ is a prebuilt query to restore a soft deleted entity
+	restoreQ string
+	// now returns the current time for auto time columns
+	now func() time.Time
+	// hooks are notified around every query
+	hooks []QueryHook
+	// replicaList is the list of configured read replicas
+	replicaList []Replica
+	// replicas serves read queries, all queries go to the primary if nil
+	replicas *replicaSet
+	// stmts caches prepared statements, queries are not prepared if nil
+	stmts *stmtCaches
 	// findByQ is a prebuilt query to find entities by operator
 	findByQ qbuilder.SelectBuilder
 	// countByQ is a prebuilt query to count entities by operator
@@ -43,32 +81,60 @@ type Relation[T any] struct {
 }
 
 // NewRelation creates a new Relation instance for the given type and table.
+// The name may be schema-qualified, e.g. "billing.invoices".
 // Relation requires a primary key to be specified at least one column (by default it is 'id').
 func NewRelation[T any](name string, db *sql.DB, opts ...Option[T]) (*Relation[T], error) {
+	schema, table := parseRelName(name)
 	rel := &Relation[T]{
-		name: pq.QuoteIdentifier(strings.Trim(name, `"`)),
-		DB:   db,
-		pk:   []string{defaultPkColumn},
+		schema:  schema,
+		table:   table,
+		DB:      db,
+		pk:      []string{defaultPkColumn},
+		queries: &sync.Map{},
+		now:     time.Now,
 	}
 	for _, o := range opts {
 		o(rel)
 	}
+	rel.name = quoteRelName(rel.schema, rel.table)
 	var err error
+	if len(rel.replicaList) > 0 {
+		if rel.replicas, err = newReplicaSet(rel.replicaList); err != nil {
+			return nil, fmt.Errorf("create '%s' replicas: %w", name, err)
+		}
+	}
 	rel.M, err = NewMeta[T](rel.pkStrategy, rel.pk...)
 	if err != nil {
 		return nil, fmt.Errorf("create '%s' meta: %w", name, err)
 	}
 
-	rel.insertQ = buildInsertQuery(rel.name, rel.M)
-	rel.updateQ = buildUpdateQuery(rel.name, rel.M)
-	rel.deleteQ = buildDeleteQuery(rel.name, rel.M)
-	rel.getOneQ = buildGetOneQuery(rel.name, rel.M)
-	rel.findByQ = buildFindByQuery(rel.name, rel.M)
-	rel.countByQ = buildCountByQuery(rel.name, rel.M)
+	if rel.softDeleteColumn != "" {
+		if _, ok := rel.M.Column(rel.softDeleteColumn); !ok {
+			return nil, fmt.Errorf("create '%s' soft delete: unknown column: %s", name, rel.softDeleteColumn)
+		}
+	}
+	rel.buildQueries()
 
 	return rel, nil
 }
 
+// buildQueries prebuilds queries of the relation
+func (r *Relation[T]) buildQueries() {
+	r.insertQ = buildInsertQuery(r.name, r.M)
+	r.updateQ = buildUpdateQuery(r.name, r.M, r.updateColumns(), r.writeScopeExpr("")...)
+	r.deleteQ = buildDeleteQuery(r.name, r.M)
+	r.upsertQ = ""
+	if checkConflictColumns(r.M, r.M.PKColumns()) == nil {
+		r.upsertQ = buildUpsertQuery(r.name, r.M, r.M.PKColumns(), r.updateColumns(), r.writeScopeExpr(r.name)...)
+	}
+	r.buildReadQueries()
+
+	if r.softDeleteColumn != "" {
+		r.softDeleteQ = buildSoftDeleteQuery(r.name, r.M, r.softDeleteColumn)
+		r.restoreQ = buildRestoreQuery(r.name, r.M, r.softDeleteColumn)
+	}
+}
+
 // Rel returns the table name
 func (r *Relation[T]) Rel() string {
 	return r.name
@@ -81,53 +147,449 @@ func (r *Relation[T]) InsertArgsFrom(e *T) []any {
 
 // UpdateArgsFrom returns arguments for update for the given entity
 func (r *Relation[T]) UpdateArgsFrom(e *T) []any {
-	return getFieldsValues(r.M.UpdateColumns().Names(), r.M, e)
+	return getFieldsValues(r.updateColumns().Names(), r.M, e)
 }
 
 // Insert inserts an entity
 func (r *Relation[T]) Insert(ctx context.Context, entity *T) error {
+	if err := beforeInsert(ctx, entity); err != nil {
+		return err
+	}
+	r.touchInsert(entity)
 	args := getFieldsValues(r.M.InsertColumns().Names(), r.M, entity)
-	row := r.DB.QueryRowContext(ctx, r.insertQ, args...)
+	row := r.hooked(ctx, "insert").QueryRowContext(ctx, r.insertQ, args...)
+	if err := scanRow(row.Scan, r.M, entity); err != nil {
+		return classifyError(err)
+	}
+	r.autoTrack(ctx, entity)
 
-	return scanRow(row.Scan, r.M, entity)
+	return afterInsert(ctx, entity)
 }
 
-// Update updates an entity
+// InsertMany inserts entities using multi-row inserts and scans returned rows back into them.
+// Entities are split into batches to stay under the bind parameters limit,
+// batches are not atomic unless executed in a transaction.
+func (r *Relation[T]) InsertMany(ctx context.Context, entities []*T) error {
+	cols := r.M.InsertColumns()
+	if len(cols) == 0 {
+		return fmt.Errorf("no columns to insert")
+	}
+	batchSize := maxBindParams / len(cols)
+
+	for start := 0; start < len(entities); start += batchSize {
+		batch := entities[start:min(start+batchSize, len(entities))]
+		if err := r.insertBatch(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertBatch inserts a single batch of entities with one query.
+func (r *Relation[T]) insertBatch(ctx context.Context, batch []*T) error {
+	names := r.M.InsertColumns().Names()
+	args := make([]any, 0, len(batch)*len(names))
+	for _, e := range batch {
+		if err := beforeInsert(ctx, e); err != nil {
+			return err
+		}
+		r.touchInsert(e)
+		args = append(args, getFieldsValues(names, r.M, e)...)
+	}
+
+	rows, err := r.hooked(ctx, "insert_many").QueryContext(ctx, buildInsertManyQuery(r.name, r.M, len(batch)), args...)
+	if err != nil {
+		return fmt.Errorf("db insert many query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var i int
+	for rows.Next() {
+		if i >= len(batch) {
+			return fmt.Errorf("db insert many: unexpected number of returned rows")
+		}
+		if err := scanRow(rows.Scan, r.M, batch[i]); err != nil {
+			return fmt.Errorf("db insert many scan: %w", err)
+		}
+		i++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("db insert many rows: %w", classifyError(err))
+	}
+	if i != len(batch) {
+		return fmt.Errorf("db insert many: expected %d returned rows, got %d", len(batch), i)
+	}
+	for _, e := range batch {
+		if err := afterInsert(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update updates an entity.
+// Only changed columns are written if the entity is tracked in the unit of work of the context, see WithTracker.
 func (r *Relation[T]) Update(ctx context.Context, entity *T) error {
-	args := getFieldsValues(r.M.UpdateColumns().Names(), r.M, entity)
+	if err := beforeUpdate(ctx, entity); err != nil {
+		return err
+	}
+	if r.isTracked(ctx, entity) {
+		if _, err := r.updateChanges(ctx, entity); err != nil {
+			return err
+		}
+		return afterUpdate(ctx, entity)
+	}
+	r.touchUpdate(entity)
+	args := r.updateArgs(r.updateColumns().Names(), entity)
+	row := r.hooked(ctx, "update").QueryRowContext(ctx, r.updateQ, args...)
+	if err := r.scanUpdated(row.Scan, entity); err != nil {
+		return err
+	}
+	r.autoTrack(ctx, entity)
+
+	return afterUpdate(ctx, entity)
+}
+
+// UpdateFields updates only the given columns of an entity
+func (r *Relation[T]) UpdateFields(ctx context.Context, entity *T, columns ...string) error {
+	if err := beforeUpdate(ctx, entity); err != nil {
+		return err
+	}
+	if err := r.updateFields(ctx, entity, columns); err != nil {
+		return err
+	}
+	return afterUpdate(ctx, entity)
+}
+
+// updateFields updates the given columns of the entity without calling hooks
+func (r *Relation[T]) updateFields(ctx context.Context, entity *T, columns []string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("no columns to update")
+	}
+	columns = r.withAutoUpdateColumns(columns)
+	query, err := r.updateFieldsQuery(columns)
+	if err != nil {
+		return err
+	}
+	r.touchUpdate(entity)
+	row := r.hooked(ctx, "update_fields").QueryRowContext(ctx, query, r.updateArgs(columns, entity)...)
+	if err := r.scanUpdated(row.Scan, entity); err != nil {
+		return err
+	}
+	r.retrack(ctx, entity)
+
+	return nil
+}
+
+// updateArgs returns arguments for update of the given columns followed by primary key and version values
+func (r *Relation[T]) updateArgs(columns []string, entity *T) []any {
+	args := getFieldsValues(columns, r.M, entity)
 	args = append(args, getFieldsValues(r.M.PKColumns().Names(), r.M, entity)...)
-	row := r.DB.QueryRowContext(ctx, r.updateQ, args...)
+	if v := r.M.VersionColumn(); v != nil {
+		args = append(args, getFieldsValues([]string{v.name}, r.M, entity)...)
+	}
+	return args
+}
 
-	return scanRow(row.Scan, r.M, entity)
+// scanUpdated scans an updated row, reports ErrStaleEntity if a versioned row was not updated
+func (r *Relation[T]) scanUpdated(sf scanFunc, entity *T) error {
+	err := classifyError(scanRow(sf, r.M, entity))
+	if errors.Is(err, ErrNotFound) && r.M.VersionColumn() != nil {
+		return ErrStaleEntity
+	}
+	return err
 }
 
-// Delete deletes an entity by given id
+// updateFieldsQuery builds and caches a query to update the given columns
+func (r *Relation[T]) updateFieldsQuery(columns []string) (string, error) {
+	key := "update:" + strings.Join(columns, ",")
+	if q, ok := r.queries.Load(key); ok {
+		return q.(string), nil
+	}
+
+	cols, err := r.M.ColumnsByNames(columns...)
+	if err != nil {
+		return "", err
+	}
+	for _, col := range cols {
+		if col.pk {
+			return "", fmt.Errorf("primary key column can not be updated: %s", col.name)
+		}
+		if col.version {
+			return "", fmt.Errorf("version column can not be updated: %s", col.name)
+		}
+	}
+	q := buildUpdateQuery(r.name, r.M, cols, r.writeScopeExpr("")...)
+	r.queries.Store(key, q)
+
+	return q, nil
+}
+
+// Delete deletes an entity by given id.
+// The entity is soft deleted if soft delete mode is enabled.
 func (r *Relation[T]) Delete(ctx context.Context, id ...any) error {
 	if len(id) != len(r.M.PKColumns()) {
 		return fmt.Errorf("invalid number of primary key columns: %d", len(id))
 	}
-	_, err := r.DB.ExecContext(ctx, r.deleteQ, id...)
-
+	if err := r.beforeDelete(ctx, id); err != nil {
+		return err
+	}
+	query := r.deleteQ
+	if r.softDeleteColumn != "" {
+		query = r.softDeleteQ
+	}
+	res, err := r.hooked(ctx, "delete").ExecContext(ctx, query, id...)
 	if err != nil {
-		return fmt.Errorf("delete record: %w", err)
+		return fmt.Errorf("delete record: %w", classifyError(err))
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	r.untrack(ctx, id)
+
 	return nil
 }
 
+// UpdateBy updates columns of all entities matching the condition and returns the number of affected rows.
+// Values of type Identifier are treated as column references.
+// ErrEmptyCond is returned for an empty condition, use Cond{All()} to update all entities.
+func (r *Relation[T]) UpdateBy(ctx context.Context, cond Cond, values map[string]any) (int64, error) {
+	query, args, err := r.buildUpdateByQuery(cond, values)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.hooked(ctx, "update_by").ExecContext(ctx, query.ToSQL(), args...)
+	if err != nil {
+		return 0, fmt.Errorf("db update by: %w", classifyError(err))
+	}
+
+	return res.RowsAffected()
+}
+
+// UpdateByReturning updates columns of all entities matching the condition and returns updated entities.
+func (r *Relation[T]) UpdateByReturning(ctx context.Context, cond Cond, values map[string]any) ([]T, error) {
+	query, args, err := r.buildUpdateByQuery(cond, values)
+	if err != nil {
+		return nil, err
+	}
+	query.Returning(r.M.Columns().Identifiers()...)
+
+	return r.queryReturning(ctx, "update_by", query.ToSQL(), args)
+}
+
+// DeleteBy deletes all entities matching the condition and returns the number of affected rows.
+// Entities are soft deleted if soft delete mode is enabled.
+// ErrEmptyCond is returned for an empty condition, use Cond{All()} to delete all entities.
+func (r *Relation[T]) DeleteBy(ctx context.Context, cond Cond) (int64, error) {
+	query, args, err := r.buildDeleteByQuery(cond, false)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.hooked(ctx, "delete_by").ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("db delete by: %w", classifyError(err))
+	}
+
+	return res.RowsAffected()
+}
+
+// DeleteByReturning deletes all entities matching the condition and returns deleted entities.
+func (r *Relation[T]) DeleteByReturning(ctx context.Context, cond Cond) ([]T, error) {
+	query, args, err := r.buildDeleteByQuery(cond, true)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.queryReturning(ctx, "delete_by", query, args)
+	for i := range items {
+		r.Untrack(ctx, &items[i])
+	}
+	return items, err
+}
+
+// buildUpdateByQuery builds a query to update given columns of entities matching the condition
+func (r *Relation[T]) buildUpdateByQuery(cond Cond, values map[string]any) (*qbuilder.UpdateBuilder, []any, error) {
+	if len(values) == 0 {
+		return nil, nil, fmt.Errorf("no columns to update")
+	}
+	args, expr := cond.Split()
+	if len(expr) == 0 && !cond.all() {
+		return nil, nil, ErrEmptyCond
+	}
+	qb := qbuilder.Update(r.name)
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		col, ok := r.M.Column(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown column: %s", name)
+		}
+		if col.pk {
+			return nil, nil, fmt.Errorf("primary key column can not be updated: %s", name)
+		}
+		if col.version {
+			return nil, nil, fmt.Errorf("version column can not be updated: %s", name)
+		}
+		if i, ok := values[name].(Identifier); ok {
+			qb.Set(col.Identifier(), i.Quoted())
+			continue
+		}
+		qb.Set(col.Identifier(), ArgsAdd(&args, values[name]))
+	}
+	for _, col := range r.M.AutoUpdateTimeColumns() {
+		if _, ok := values[col.name]; !ok {
+			qb.Set(col.Identifier(), ArgsAdd(&args, r.now()))
+		}
+	}
+	if v := r.M.VersionColumn(); v != nil {
+		qb.Set(v.Identifier(), v.Identifier()+" + 1")
+	}
+	for _, e := range expr {
+		qb.AndWhere(e)
+	}
+	for _, e := range r.writeScopeExpr("") {
+		qb.AndWhere(e)
+	}
+
+	return qb, args, nil
+}
+
+// buildDeleteByQuery builds a query to delete or soft delete entities matching the condition
+func (r *Relation[T]) buildDeleteByQuery(cond Cond, returning bool) (string, []any, error) {
+	args, expr := cond.Split()
+	if len(expr) == 0 && !cond.all() {
+		return "", nil, ErrEmptyCond
+	}
+	if r.softDeleteColumn != "" {
+		column := pq.QuoteIdentifier(r.softDeleteColumn)
+		qb := qbuilder.Update(r.name)
+		qb.Set(column, "now()")
+		for _, e := range expr {
+			qb.AndWhere(e)
+		}
+		qb.AndWhere(column + " IS NULL")
+		if returning {
+			qb.Returning(r.M.Columns().Identifiers()...)
+		}
+		return qb.ToSQL(), args, nil
+	}
+
+	qb := qbuilder.Delete(r.name)
+	for _, e := range expr {
+		qb.AndWhere(e)
+	}
+	if returning {
+		qb.Returning(r.M.Columns().Identifiers()...)
+	}
+
+	return qb.ToSQL(), args, nil
+}
+
+// queryReturning executes a query and scans all returned rows
+func (r *Relation[T]) queryReturning(ctx context.Context, op, query string, args []any) ([]T, error) {
+	rows, err := r.hooked(ctx, op).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db query returning: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var items []T
+	for rows.Next() {
+		var entity T
+		if err := scanRow(rows.Scan, r.M, &entity); err != nil {
+			return nil, fmt.Errorf("db query returning scan: %w", err)
+		}
+		r.retrack(ctx, &entity)
+		items = append(items, entity)
+	}
+
+	return items, classifyError(rows.Err())
+}
+
 // Find finds single entity by given id
 func (r *Relation[T]) Find(ctx context.Context, id ...any) (T, error) {
 	var entity T
 	if len(id) != len(r.M.PKColumns()) {
 		return entity, fmt.Errorf("invalid number of primary key columns: %d", len(id))
 	}
-	row := r.DB.QueryRowContext(ctx, r.getOneQ, id...)
+	row := r.reader(ctx, "find").QueryRowContext(ctx, r.getOneQ, id...)
+	if err := r.Scan(row.Scan, &entity); err != nil {
+		return entity, classifyError(err)
+	}
+	r.autoTrack(ctx, &entity)
+
+	return entity, afterFind(ctx, &entity)
+}
 
-	return entity, r.Scan(row.Scan, &entity)
+// FindWith finds an entity by given id applying find options, e.g. Preload or Select.
+// The id of a composite primary key is given as []any in the primary key columns order.
+func (r *Relation[T]) FindWith(ctx context.Context, id any, opts ...FindOption) (T, error) {
+	ids, ok := id.([]any)
+	if !ok {
+		ids = []any{id}
+	}
+	if len(opts) == 0 {
+		return r.Find(ctx, ids...)
+	}
+	if len(ids) != len(r.M.PKColumns()) {
+		var entity T
+		return entity, fmt.Errorf("invalid number of primary key columns: %d", len(ids))
+	}
+	cond := make(Cond, len(ids))
+	for i, col := range r.M.PKColumns() {
+		cond[i] = Eq(col.name, ids[i])
+	}
+	return r.FindOneBy(ctx, cond, opts...)
 }
 
 // FindBy finds all entities by given operator
-func (r *Relation[T]) FindBy(ctx context.Context, cond Cond, sort Sort, pag Pagination) ([]T, error) {
+func (r *Relation[T]) FindBy(ctx context.Context, cond Cond, sort Sort, pag Pagination, opts ...FindOption) ([]T, error) {
 	var items []T
+	o := newFindOptions(opts)
+	query, args := r.buildFindBy(cond, sort, pag)
+	columns, err := r.project(&query, o)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.reader(ctx, "find_by").QueryContext(ctx, query.ToSQL(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("db find by query: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity T
+		if err := scanColumns(rows.Scan, r.M, columns, &entity); err != nil {
+			return nil, fmt.Errorf("db find by scan: %w", err)
+		}
+		if len(o.columns) == 0 {
+			r.autoTrack(ctx, &entity)
+		}
+		items = append(items, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db find by rows: %w", classifyError(err))
+	}
+	if len(o.preload) > 0 {
+		entities := make([]*T, len(items))
+		for i := range items {
+			entities[i] = &items[i]
+		}
+		if err := r.preload(ctx, o, entities); err != nil {
+			return nil, err
+		}
+	}
+	for i := range items {
+		if err := afterFind(ctx, &items[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// buildFindBy builds a query to find entities by given condition, sort and pagination
+func (r *Relation[T]) buildFindBy(cond Cond, sort Sort, pag Pagination) (qbuilder.SelectBuilder, []any) {
 	query := r.findByQ.Copy()
 	args, expr := cond.Split()
 
@@ -150,21 +612,7 @@ func (r *Relation[T]) FindBy(ctx context.Context, cond Cond, sort Sort, pag Pagi
 		query.Offset(offset)
 	}
 
-	rows, err := r.DB.QueryContext(ctx, query.ToSQL(), args...)
-	if err != nil {
-		return nil, fmt.Errorf("db find by query: %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var entity T
-		if err := scanRow(rows.Scan, r.M, &entity); err != nil {
-			return nil, fmt.Errorf("db find by scan: %w", err)
-		}
-		items = append(items, entity)
-	}
-
-	return items, nil
+	return query, args
 }
 
 // CountBy counts all entities by given condition
@@ -179,14 +627,15 @@ func (r *Relation[T]) CountBy(ctx context.Context, cond Cond) (int64, error) {
 		}
 	}
 
-	row := r.DB.QueryRowContext(ctx, query.ToSQL(), args...)
+	row := r.reader(ctx, "count_by").QueryRowContext(ctx, query.ToSQL(), args...)
 
-	return count, row.Scan(&count)
+	return count, classifyError(row.Scan(&count))
 }
 
 // FindOneBy finds single entity by given operator
-func (r *Relation[T]) FindOneBy(ctx context.Context, cond Cond) (T, error) {
+func (r *Relation[T]) FindOneBy(ctx context.Context, cond Cond, opts ...FindOption) (T, error) {
 	var entity T
+	o := newFindOptions(opts)
 	query := r.findByQ.Copy()
 	args, expr := cond.Split()
 
@@ -196,9 +645,22 @@ func (r *Relation[T]) FindOneBy(ctx context.Context, cond Cond) (T, error) {
 		}
 	}
 	query.Limit(1)
-	row := r.DB.QueryRowContext(ctx, query.ToSQL(), args...)
+	columns, err := r.project(&query, o)
+	if err != nil {
+		return entity, err
+	}
+	row := r.reader(ctx, "find_one_by").QueryRowContext(ctx, query.ToSQL(), args...)
+	if err := scanColumns(row.Scan, r.M, columns, &entity); err != nil {
+		return entity, classifyError(err)
+	}
+	if len(o.columns) == 0 {
+		r.autoTrack(ctx, &entity)
+	}
+	if err := r.preload(ctx, o, []*T{&entity}); err != nil {
+		return entity, err
+	}
 
-	return entity, r.Scan(row.Scan, &entity)
+	return entity, afterFind(ctx, &entity)
 }
 
 // Scan scans a single row into the entity
@@ -216,20 +678,49 @@ func buildInsertQuery[T any](rel string, m *Metadata[T]) string {
 	return qb.ToSQL()
 }
 
-// buildUpdateQuery prebuilds a query to update an entity
-func buildUpdateQuery[T any](rel string, m *Metadata[T]) string {
+// buildInsertManyQuery builds a query to insert n entities at once
+func buildInsertManyQuery[T any](rel string, m *Metadata[T], n int) string {
+	qb := qbuilder.Insert(rel)
+	qb.Columns(m.InsertColumns().Identifiers()...)
+
+	cols := len(m.InsertColumns())
+	values := make([][]string, n)
+	for i := range values {
+		values[i] = make([]string, cols)
+		for j := range values[i] {
+			values[i][j] = "$" + strconv.Itoa(i*cols+j+1)
+		}
+	}
+	qb.Values(values...)
+	qb.Returning(m.Columns().Identifiers()...)
+
+	return qb.ToSQL()
+}
+
+// buildUpdateQuery prebuilds a query to update given columns of an entity.
+// The version column is incremented and checked if specified.
+func buildUpdateQuery[T any](rel string, m *Metadata[T], cols ListColumnMeta, where ...string) string {
 	qb := qbuilder.Update(rel)
 
 	var i int
-	for _, col := range m.UpdateColumns() {
+	for _, col := range cols {
 		qb.Set(col.Identifier(), "$"+strconv.Itoa(i+1))
 		i++
 	}
+	if v := m.VersionColumn(); v != nil {
+		qb.Set(v.Identifier(), v.Identifier()+" + 1")
+	}
 
 	for _, col := range m.PKColumns() {
 		qb.AndWhere(col.Identifier() + " = $" + strconv.Itoa(i+1))
 		i++
 	}
+	if v := m.VersionColumn(); v != nil {
+		qb.AndWhere(v.Identifier() + " = $" + strconv.Itoa(i+1))
+	}
+	for _, e := range where {
+		qb.AndWhere(e)
+	}
 	qb.Returning(m.Columns().Identifiers()...)
 
 	return qb.ToSQL()
@@ -247,29 +738,38 @@ func buildDeleteQuery[T any](rel string, m *Metadata[T]) string {
 }
 
 // buildGetOneQuery prebuilds a query to get a single entity
-func buildGetOneQuery[T any](rel string, m *Metadata[T]) string {
+func buildGetOneQuery[T any](rel string, m *Metadata[T], where ...string) string {
 	qb := qbuilder.Select(m.Columns().Identifiers()...)
 	qb.From(rel)
 
 	for i, col := range m.PKColumns() {
 		qb.AndWhere(col.Identifier() + " = $" + strconv.Itoa(i+1))
 	}
+	for _, e := range where {
+		qb.AndWhere(e)
+	}
 
 	return qb.Limit(1).ToSQL()
 }
 
 // buildFindByQuery prebuilds a query to find many entities
-func buildFindByQuery[T any](rel string, m *Metadata[T]) qbuilder.SelectBuilder {
+func buildFindByQuery[T any](rel string, m *Metadata[T], where ...string) qbuilder.SelectBuilder {
 	qb := qbuilder.Select(m.Columns().Identifiers()...)
 	qb.From(rel)
+	for _, e := range where {
+		qb.AndWhere(e)
+	}
 
 	return qb.Copy()
 }
 
-// buildFindByQuery prebuilds a query to find many entities
-func buildCountByQuery[T any](rel string, m *Metadata[T]) qbuilder.SelectBuilder {
+// buildCountByQuery prebuilds a query to count entities
+func buildCountByQuery[T any](rel string, m *Metadata[T], where ...string) qbuilder.SelectBuilder {
 	qb := qbuilder.Select("COUNT(*)")
 	qb.From(rel)
+	for _, e := range where {
+		qb.AndWhere(e)
+	}
 
 	return qb.Copy()
 }
@@ -2,7 +2,10 @@ package rel
 
 import (
 	"context"
+	"errors"
 	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -67,6 +70,58 @@ func TestRelationSerial_InsertSerialID(t *testing.T) {
 	}
 }
 
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelationSerial_InsertMany(t *testing.T) {
+	now := time.Now()
+	entities := []*entitySerialID{
+		{Name: "First", TimeStamps: TimeStamps{Created: now, Updated: now}},
+		{Name: "Second", TimeStamps: TimeStamps{Created: now, Updated: now}},
+	}
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	eq := mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("created", "updated", "name") VALUES ($1, $2, $3), ($4, $5, $6) RETURNING "created", "updated", "id", "name"`))
+	eq.WithArgs(now, now, "First", now, now, "Second")
+	eq.WillReturnRows(
+		sqlmock.NewRows([]string{"created", "updated", "id", "name"}).
+			AddRow(now, now, 1, "First").
+			AddRow(now, now, 2, "Second"),
+	)
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	if err = rel.InsertMany(context.Background(), entities); err != nil {
+		t.Fatalf("failed to insert entities: %v", err)
+	}
+	for i, ent := range entities {
+		if ent.ID != int64(i+1) {
+			t.Fatalf("unexpected ID: %d", ent.ID)
+		}
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestBuildInsertManyQuery_Batch(t *testing.T) {
+	m, err := NewMeta[entitySerialID](0, "id")
+	if err != nil {
+		t.Fatalf("failed to create meta: %v", err)
+	}
+	batchSize := maxBindParams / len(m.InsertColumns())
+	q := buildInsertManyQuery(`"entities"`, m, batchSize)
+
+	last := "$" + strconv.Itoa(batchSize*len(m.InsertColumns()))
+	if !strings.Contains(q, last+")") || strings.Contains(q, "$"+strconv.Itoa(maxBindParams+1)) {
+		t.Fatalf("unexpected placeholders in batch query")
+	}
+}
+
 //goland:noinspection SqlNoDataSourceInspection
 func TestRelationSerial_UpdateSerialID(t *testing.T) {
 	tests := []struct {
@@ -115,6 +170,117 @@ func TestRelationSerial_UpdateSerialID(t *testing.T) {
 	}
 }
 
+type entityVersioned struct {
+	ID      int64  `db:"id"`
+	Name    string `db:"name"`
+	Version int64  `db:"version,version"`
+}
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelationVersioned_Update(t *testing.T) {
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+		err  error
+	}{
+		{
+			name: "successful update",
+			rows: sqlmock.NewRows([]string{"id", "name", "version"}).AddRow(1, "Test Name", 3),
+		},
+		{
+			name: "stale entity",
+			rows: sqlmock.NewRows([]string{"id", "name", "version"}),
+			err:  ErrStaleEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock db: %v", err)
+			}
+			eq := mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1, "version" = "version" + 1 WHERE "id" = $2 AND "version" = $3 RETURNING "id", "name", "version"`))
+			eq.WithArgs("Test Name", 1, 2)
+			eq.WillReturnRows(tt.rows)
+
+			rel, err := NewRelation[entityVersioned]("entities", mockDB)
+			if err != nil {
+				t.Fatalf("failed to create relation: %v", err)
+			}
+
+			ent := entityVersioned{ID: 1, Name: "Test Name", Version: 2}
+			if err = rel.Update(context.Background(), &ent); !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err == nil && ent.Version != 3 {
+				t.Fatalf("unexpected version: %d", ent.Version)
+			}
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelationSerial_UpdateFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		columns   []string
+		query     string
+		expectErr bool
+	}{
+		{
+			name:    "successful update",
+			columns: []string{"name"},
+			query:   `UPDATE "entities" SET "name" = $1 WHERE "id" = $2 RETURNING "created", "updated", "id", "name"`,
+		},
+		{
+			name:      "unknown column",
+			columns:   []string{"email"},
+			expectErr: true,
+		},
+		{
+			name:      "primary key column",
+			columns:   []string{"id"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock db: %v", err)
+			}
+			now := time.Now()
+			if tt.query != "" {
+				eq := mock.ExpectQuery(regexp.QuoteMeta(tt.query))
+				eq.WithArgs("Test Name", 1)
+				eq.WillReturnRows(
+					sqlmock.NewRows([]string{"created", "updated", "id", "name"}).
+						AddRow(now, now, 1, "Test Name"),
+				)
+			}
+
+			rel, err := NewRelation[entitySerialID]("entities", mockDB)
+			if err != nil {
+				t.Fatalf("failed to create relation: %v", err)
+			}
+
+			ent := entitySerialID{ID: 1, Name: "Test Name"}
+			err = rel.UpdateFields(context.Background(), &ent, tt.columns...)
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.expectErr && ent.Created != now {
+				t.Fatalf("unexpected Created: %v", ent.Created)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
 //goland:noinspection SqlNoDataSourceInspection,SqlResolve
 func TestRelationSerial_DeleteSerialID(t *testing.T) {
 	tests := []struct {
@@ -159,6 +325,110 @@ func TestRelationSerial_DeleteSerialID(t *testing.T) {
 	}
 }
 
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelationSerial_UpdateBy(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $2, "updated" = "created" WHERE "name" = $1`)).
+		WithArgs("Old Name", "New Name").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $2 WHERE "id" IN ($1) RETURNING "created", "updated", "id", "name"`)).
+		WithArgs(1, "New Name").
+		WillReturnRows(sqlmock.NewRows([]string{"created", "updated", "id", "name"}).AddRow(now, now, 1, "New Name"))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	n, err := rel.UpdateBy(context.Background(), Cond{Eq("name", "Old Name")}, map[string]any{
+		"name":    "New Name",
+		"updated": Identifier("created"),
+	})
+	if err != nil {
+		t.Fatalf("failed to update entities: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("unexpected affected rows: %d", n)
+	}
+
+	ents, err := rel.UpdateByReturning(context.Background(), Cond{In("id", 1)}, map[string]any{"name": "New Name"})
+	if err != nil {
+		t.Fatalf("failed to update entities: %v", err)
+	}
+	if len(ents) != 1 || ents[0].Name != "New Name" {
+		t.Fatalf("unexpected result: %v", ents)
+	}
+
+	if _, err = rel.UpdateBy(context.Background(), Cond{All()}, map[string]any{"id": 2}); err == nil {
+		t.Fatalf("expected error for primary key column")
+	}
+	if _, err = rel.UpdateBy(context.Background(), Cond{All()}, map[string]any{"email": "x"}); err == nil {
+		t.Fatalf("expected error for unknown column")
+	}
+	if _, err = rel.UpdateBy(context.Background(), nil, map[string]any{"name": "x"}); !errors.Is(err, ErrEmptyCond) {
+		t.Fatalf("expected empty condition error, got: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelationSerial_DeleteBy(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "created" < $1`)).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "name" = $1 RETURNING "created", "updated", "id", "name"`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows([]string{"created", "updated", "id", "name"}).AddRow(now, now, 1, "Test Name"))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities"`)).
+		WithoutArgs().
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	n, err := rel.DeleteBy(context.Background(), Cond{Lt("created", now)})
+	if err != nil {
+		t.Fatalf("failed to delete entities: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("unexpected affected rows: %d", n)
+	}
+
+	ents, err := rel.DeleteByReturning(context.Background(), Cond{Eq("name", "Test Name")})
+	if err != nil {
+		t.Fatalf("failed to delete entities: %v", err)
+	}
+	if len(ents) != 1 || ents[0].ID != 1 {
+		t.Fatalf("unexpected result: %v", ents)
+	}
+
+	if _, err = rel.DeleteBy(context.Background(), nil); !errors.Is(err, ErrEmptyCond) {
+		t.Fatalf("expected empty condition error, got: %v", err)
+	}
+	if _, err = rel.DeleteByReturning(context.Background(), Cond{}); !errors.Is(err, ErrEmptyCond) {
+		t.Fatalf("expected empty condition error, got: %v", err)
+	}
+	if n, err = rel.DeleteBy(context.Background(), Cond{All()}); err != nil || n != 5 {
+		t.Fatalf("failed to delete all entities: %d, %v", n, err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 //goland:noinspection SqlNoDataSourceInspection,SqlResolve
 func TestRelationSerial_Find(t *testing.T) {
 	tests := []struct {
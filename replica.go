@@ -0,0 +1,92 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+type primaryCtxKey struct{}
+
+// Replica is a read replica with a relative weight of the read traffic it receives.
+type Replica struct {
+	DB     *sql.DB
+	Weight int
+}
+
+// Replicas routes read queries to the given replicas in round-robin order.
+// Find, FindBy, FindOneBy, CountBy and other read-only queries go to replicas,
+// while writes and all queries inside a transaction go to the primary.
+func Replicas[T any](dbs ...*sql.DB) Option[T] {
+	return func(r *Relation[T]) {
+		for _, db := range dbs {
+			r.replicaList = append(r.replicaList, Replica{DB: db, Weight: 1})
+		}
+	}
+}
+
+// WeightedReplicas routes read queries to the given replicas proportionally to their weights.
+func WeightedReplicas[T any](replicas ...Replica) Option[T] {
+	return func(r *Relation[T]) {
+		r.replicaList = append(r.replicaList, replicas...)
+	}
+}
+
+// UsePrimary returns a context that routes read queries to the primary, e.g. to read your own writes.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryCtxKey{}, true)
+}
+
+// replicaSet picks replicas in weighted round-robin order.
+type replicaSet struct {
+	slots []*sql.DB
+	next  atomic.Uint64
+}
+
+// newReplicaSet creates a replica set, every replica takes as many slots as its weight.
+func newReplicaSet(replicas []Replica) (*replicaSet, error) {
+	s := &replicaSet{}
+	for _, rp := range replicas {
+		if rp.DB == nil {
+			return nil, fmt.Errorf("replica db is nil")
+		}
+		if rp.Weight < 1 {
+			return nil, fmt.Errorf("replica weight must be positive: %d", rp.Weight)
+		}
+	}
+	// interleave slots of replicas to spread consecutive reads
+	for round := 0; ; round++ {
+		added := false
+		for _, rp := range replicas {
+			if round < rp.Weight {
+				s.slots = append(s.slots, rp.DB)
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return s, nil
+}
+
+// pick returns the next replica.
+func (s *replicaSet) pick() *sql.DB {
+	return s.slots[(s.next.Add(1)-1)%uint64(len(s.slots))]
+}
+
+// reader returns the querier for the read operation notifying query hooks of the relation.
+// Reads go to a replica unless the relation or the context has a transaction or the context requires the primary.
+func (r *Relation[T]) reader(ctx context.Context, op string) Querier {
+	if r.replicas == nil || r.q != nil {
+		return r.hooked(ctx, op)
+	}
+	if _, ok := txFromContext(ctx, r.DB); ok {
+		return r.hooked(ctx, op)
+	}
+	if primary, _ := ctx.Value(primaryCtxKey{}).(bool); primary {
+		return r.hooked(ctx, op)
+	}
+	return r.withHooks(r.prepared(r.replicas.pick()), op)
+}
@@ -0,0 +1,76 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestNewReplicaSet(t *testing.T) {
+	a, b := &sql.DB{}, &sql.DB{}
+	s, err := newReplicaSet([]Replica{{DB: a, Weight: 2}, {DB: b, Weight: 1}})
+	if err != nil {
+		t.Fatalf("failed to create replica set: %v", err)
+	}
+	expected := []*sql.DB{a, b, a, a, b, a}
+	for i, db := range expected {
+		if got := s.pick(); got != db {
+			t.Fatalf("unexpected replica at %d", i)
+		}
+	}
+
+	if _, err = newReplicaSet([]Replica{{DB: a}}); err == nil {
+		t.Fatalf("expected error for zero weight")
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_Replicas(t *testing.T) {
+	primaryDB, primary, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	replicaDB, replica, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	countQ := regexp.QuoteMeta(`SELECT COUNT(*) FROM "entities"`)
+	replica.ExpectQuery(countQ).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	primary.ExpectQuery(countQ).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	primary.ExpectBegin()
+	primary.ExpectQuery(countQ).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	primary.ExpectCommit()
+
+	rel, err := NewRelation[entitySerialID]("entities", primaryDB, Replicas[entitySerialID](replicaDB))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	if n, err := rel.CountBy(ctx, nil); err != nil || n != 1 {
+		t.Fatalf("expected count from replica, got %d: %v", n, err)
+	}
+	if n, err := rel.CountBy(UsePrimary(ctx), nil); err != nil || n != 2 {
+		t.Fatalf("expected count from primary, got %d: %v", n, err)
+	}
+	err = NewTxManager(primaryDB).RunInTx(ctx, func(ctx context.Context) error {
+		n, err := rel.CountBy(ctx, nil)
+		if err == nil && n != 3 {
+			t.Errorf("expected count from primary in tx, got %d", n)
+		}
+		return err
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to run in tx: %v", err)
+	}
+
+	if err = primary.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet primary expectations: %v", err)
+	}
+	if err = replica.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet replica expectations: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package rel
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pgClassConnectionException is the class of postgres connection exception error codes.
+const pgClassConnectionException = "08"
+
+// RetryPolicy defines how TxManager.RunInTx re-runs transactions failed with a serialization failure,
+// a deadlock or a transient connection error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt, it doubles for every next attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, not capped if zero.
+	MaxDelay time.Duration
+}
+
+// ErrRetryExhausted is returned when a transaction was retried and the last attempt failed with a retryable error.
+// Non retryable errors are returned as is.
+type ErrRetryExhausted struct {
+	Attempts int
+	Err      error
+}
+
+func (e *ErrRetryExhausted) Error() string {
+	return fmt.Sprintf("tx failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *ErrRetryExhausted) Unwrap() error {
+	return e.Err
+}
+
+// WithRetry returns a copy of the manager that retries transactions according to the policy.
+// The function passed to RunInTx must be safe to run several times. Nested calls are not retried,
+// the outermost transaction is retried as a whole.
+func (m *TxManager) WithRetry(p RetryPolicy) *TxManager {
+	cp := *m
+	cp.retry = &p
+	return &cp
+}
+
+// runWithRetry runs the transaction attempt until it succeeds, fails with a non retryable error
+// or the attempts are exhausted.
+func (p *RetryPolicy) runWithRetry(ctx context.Context, attempt func() error) error {
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		if n >= p.MaxAttempts {
+			return exhausted(n, err)
+		}
+
+		t := time.NewTimer(p.delay(n))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return exhausted(n, errors.Join(err, ctx.Err()))
+		case <-t.C:
+		}
+	}
+}
+
+// exhausted wraps the error of the last attempt in ErrRetryExhausted if the transaction was retried.
+func exhausted(attempts int, err error) error {
+	if attempts < 2 {
+		return err
+	}
+	return &ErrRetryExhausted{Attempts: attempts, Err: err}
+}
+
+// delay returns the delay after the given attempt: exponential backoff with equal jitter.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << min(attempt-1, 30)
+	if d <= 0 {
+		return 0
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// isRetryable reports whether the transaction failed with a serialization failure, a deadlock
+// or a transient connection error and can be run again.
+// Context errors are never retried. A failed COMMIT is retried only on a serialization failure or a deadlock,
+// a connection error after COMMIT was sent leaves the outcome unknown.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pgCodeSerializationFailure, pgCodeDeadlockDetected:
+			return true
+		}
+	}
+	var commitErr *errCommit
+	if errors.As(err, &commitErr) {
+		return false
+	}
+	if pqErr != nil {
+		return strings.HasPrefix(string(pqErr.Code), pgClassConnectionException)
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr)
+}
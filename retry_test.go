@@ -0,0 +1,135 @@
+package rel
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "serialization failure", err: &ErrSerializationFailure{Err: &pq.Error{Code: pgCodeSerializationFailure}}, expected: true},
+		{name: "deadlock", err: &pq.Error{Code: pgCodeDeadlockDetected}, expected: true},
+		{name: "commit serialization failure", err: &errCommit{err: &ErrSerializationFailure{Err: &pq.Error{Code: pgCodeSerializationFailure}}}, expected: true},
+		{name: "connection failure", err: &pq.Error{Code: "08006"}, expected: true},
+		{name: "bad connection", err: driver.ErrBadConn, expected: true},
+		{name: "network error", err: fmt.Errorf("exec: %w", &net.OpError{Op: "read", Err: errors.New("connection reset")}), expected: true},
+		{name: "commit connection failure", err: &errCommit{err: &pq.Error{Code: "08006"}}, expected: false},
+		{name: "commit bad connection", err: &errCommit{err: driver.ErrBadConn}, expected: false},
+		{name: "deadline exceeded", err: fmt.Errorf("exec: %w", context.DeadlineExceeded), expected: false},
+		{name: "canceled", err: context.Canceled, expected: false},
+		{name: "unique violation", err: &pq.Error{Code: pgCodeUniqueViolation}, expected: false},
+		{name: "not found", err: ErrNotFound, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 30 * time.Millisecond}
+	for attempt, maxDelay := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 5: 30 * time.Millisecond} {
+		if d := p.delay(attempt); d < maxDelay/2 || d > maxDelay {
+			t.Errorf("unexpected delay after attempt %d: %s", attempt, d)
+		}
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestTxManager_RunInTxRetry(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	deleteQ := regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)
+	serializationErr := &pq.Error{Code: pgCodeSerializationFailure, Message: "could not serialize access"}
+
+	// retried once and committed
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteQ).WithArgs(1).WillReturnError(serializationErr)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteQ).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// attempts exhausted
+	for range 2 {
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteQ).WithArgs(1).WillReturnError(serializationErr)
+		mock.ExpectRollback()
+	}
+	// not retryable error of the first attempt
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteQ).WithArgs(1).WillReturnError(&pq.Error{Code: pgCodeUniqueViolation})
+	mock.ExpectRollback()
+	// connection error of commit is not retried
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteQ).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(&pq.Error{Code: "08006"})
+	// not retryable error after a retry
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteQ).WithArgs(1).WillReturnError(serializationErr)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(deleteQ).WithArgs(1).WillReturnError(&pq.Error{Code: pgCodeUniqueViolation})
+	mock.ExpectRollback()
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	m := NewTxManager(mockDB).WithRetry(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+	fn := func(ctx context.Context) error {
+		return rel.Delete(ctx, 1)
+	}
+
+	if err = m.RunInTx(context.Background(), fn, nil); err != nil {
+		t.Fatalf("expected retried tx to succeed, got: %v", err)
+	}
+
+	err = m.RunInTx(context.Background(), fn, nil)
+	var exhausted *ErrRetryExhausted
+	if !errors.As(err, &exhausted) || exhausted.Attempts != 2 {
+		t.Fatalf("expected retry exhausted after 2 attempts, got: %v", err)
+	}
+	var serialization *ErrSerializationFailure
+	if !errors.As(err, &serialization) {
+		t.Fatalf("expected serialization failure, got: %v", err)
+	}
+
+	err = m.RunInTx(context.Background(), fn, nil)
+	var unique *ErrUniqueViolation
+	if !errors.As(err, &unique) || errors.As(err, &exhausted) {
+		t.Fatalf("expected unwrapped unique violation, got: %v", err)
+	}
+
+	err = m.RunInTx(context.Background(), fn, nil)
+	var commitErr *errCommit
+	if !errors.As(err, &commitErr) || errors.As(err, &exhausted) {
+		t.Fatalf("expected unwrapped commit failure, got: %v", err)
+	}
+
+	err = m.RunInTx(context.Background(), fn, nil)
+	if !errors.As(err, &unique) || errors.As(err, &exhausted) {
+		t.Fatalf("expected unwrapped unique violation after a retry, got: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
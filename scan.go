@@ -9,7 +9,12 @@ type scanFunc func(dest ...any) error
 
 // scanRow scans all struct fields using dst fields as a destination.
 func scanRow[T any](scan scanFunc, m *Metadata[T], dst *T) error {
-	pointers, err := getFieldsPointers[T](m.Columns().Names(), m, dst)
+	return scanColumns(scan, m, m.Columns().Names(), dst)
+}
+
+// scanColumns scans the given columns using dst fields as a destination.
+func scanColumns[T any](scan scanFunc, m *Metadata[T], columns []string, dst *T) error {
+	pointers, err := getFieldsPointers[T](columns, m, dst)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,81 @@
+package rel
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// Schema sets the schema of the relation, it overrides the schema of a qualified relation name.
+func Schema[T any](schema string) Option[T] {
+	return func(r *Relation[T]) {
+		r.schema = schema
+	}
+}
+
+// InSchema returns a copy of the relation for the same table in another schema, e.g. of a tenant.
+// Queries are rebuilt on every call, so keep the returned relation to reuse it.
+// Cached statements are not shared with the original relation.
+func (r *Relation[T]) InSchema(schema string) *Relation[T] {
+	cp := *r
+	cp.schema = schema
+	cp.name = quoteRelName(schema, r.table)
+	cp.queries = &sync.Map{}
+	if r.stmts != nil {
+		cp.stmts = &stmtCaches{size: r.stmts.size}
+	}
+	cp.buildQueries()
+	return &cp
+}
+
+// Schema returns the schema of the relation, it is empty if the search path is used.
+func (r *Relation[T]) Schema() string {
+	return r.schema
+}
+
+// qualifiedTable returns the unquoted schema-qualified relation name.
+func (r *Relation[T]) qualifiedTable() string {
+	if r.schema == "" {
+		return r.table
+	}
+	return r.schema + "." + r.table
+}
+
+// parseRelName splits an optionally schema-qualified and quoted relation name on the first unquoted dot,
+// e.g. billing.invoices, "billing"."invoices" or billing."Invoices". Every part is unquoted separately.
+func parseRelName(name string) (schema, table string) {
+	quoted := false
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '"':
+			quoted = !quoted
+		case '.':
+			if !quoted {
+				return unquoteName(name[:i]), unquoteName(name[i+1:])
+			}
+		}
+	}
+	return "", unquoteName(name)
+}
+
+// unquoteName removes quotes of a quoted identifier and unescapes doubled quotes inside.
+func unquoteName(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return name
+}
+
+// quoteRelName returns the quoted relation name, every part of a schema-qualified name is quoted separately.
+func quoteRelName(schema, table string) string {
+	if schema == "" {
+		return pq.QuoteIdentifier(table)
+	}
+	return pq.QuoteIdentifier(schema) + "." + pq.QuoteIdentifier(table)
+}
+
+// quoteName parses and quotes an optionally schema-qualified relation name.
+func quoteName(name string) string {
+	return quoteRelName(parseRelName(name))
+}
@@ -0,0 +1,81 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestParseRelName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "invoices", expected: `"invoices"`},
+		{name: `"invoices"`, expected: `"invoices"`},
+		{name: "billing.invoices", expected: `"billing"."invoices"`},
+		{name: `"billing"."invoices"`, expected: `"billing"."invoices"`},
+		{name: `"billing.invoices"`, expected: `"billing.invoices"`},
+		{name: `billing."Invoices"`, expected: `"billing"."Invoices"`},
+		{name: `"billing".invoices`, expected: `"billing"."invoices"`},
+		{name: `"Billing"."Invoices.2024"`, expected: `"Billing"."Invoices.2024"`},
+		{name: `"a""b".invoices`, expected: `"a""b"."invoices"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteName(tt.name); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_Schema(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"created", "updated", "id", "name"}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "billing"."invoices" WHERE "id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(created, created, 1, "Test Name"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "tenant_1"."invoices" WHERE "id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(created, created, 1, "Test Name"))
+
+	rel, err := NewRelation[entitySerialID]("billing.invoices", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	if rel.Rel() != `"billing"."invoices"` || rel.Schema() != "billing" {
+		t.Fatalf("unexpected relation name: %s", rel.Rel())
+	}
+	if _, err = rel.Find(context.Background(), 1); err != nil {
+		t.Fatalf("failed to find: %v", err)
+	}
+
+	tenant := rel.InSchema("tenant_1")
+	if _, err = tenant.Find(context.Background(), 1); err != nil {
+		t.Fatalf("failed to find in tenant schema: %v", err)
+	}
+	if rel.Rel() != `"billing"."invoices"` {
+		t.Fatalf("original relation is changed: %s", rel.Rel())
+	}
+
+	rel, err = NewRelation[entitySerialID]("invoices", mockDB, Schema[entitySerialID]("archive"))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	if rel.Rel() != `"archive"."invoices"` {
+		t.Fatalf("unexpected relation name: %s", rel.Rel())
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
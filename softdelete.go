@@ -0,0 +1,172 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/slmder/rel/qbuilder"
+)
+
+// trashedScope defines which soft deleted entities are visible to reads.
+type trashedScope int
+
+const (
+	// scopeWithoutTrashed hides soft deleted entities.
+	scopeWithoutTrashed trashedScope = iota
+	// scopeWithTrashed shows all entities.
+	scopeWithTrashed
+	// scopeOnlyTrashed shows only soft deleted entities.
+	scopeOnlyTrashed
+)
+
+// SoftDelete enables soft delete mode using the given timestamp column.
+// Delete sets the column to now() instead of removing the row,
+// Find, FindBy, FindOneBy and CountBy skip rows where the column is not null.
+// Update, UpdateFields, UpdateBy and Upsert never write soft deleted rows and do not write the column by default,
+// use Restore instead.
+func SoftDelete[T any](column string) Option[T] {
+	return func(r *Relation[T]) {
+		r.softDeleteColumn = column
+	}
+}
+
+// WithTrashed returns a copy of the relation that reads soft deleted entities as well.
+func (r *Relation[T]) WithTrashed() *Relation[T] {
+	return r.withScope(scopeWithTrashed)
+}
+
+// OnlyTrashed returns a copy of the relation that reads only soft deleted entities.
+func (r *Relation[T]) OnlyTrashed() *Relation[T] {
+	return r.withScope(scopeOnlyTrashed)
+}
+
+// Restore restores a soft deleted entity by given id.
+// ErrNotFound is returned if the entity does not exist or is not deleted.
+func (r *Relation[T]) Restore(ctx context.Context, id ...any) error {
+	if r.softDeleteColumn == "" {
+		return fmt.Errorf("soft delete is not enabled")
+	}
+	if len(id) != len(r.M.PKColumns()) {
+		return fmt.Errorf("invalid number of primary key columns: %d", len(id))
+	}
+	res, err := r.hooked(ctx, "restore").ExecContext(ctx, r.restoreQ, id...)
+	if err != nil {
+		return fmt.Errorf("restore record: %w", classifyError(err))
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ForceDelete permanently deletes an entity by given id regardless of soft delete mode.
+func (r *Relation[T]) ForceDelete(ctx context.Context, id ...any) error {
+	if len(id) != len(r.M.PKColumns()) {
+		return fmt.Errorf("invalid number of primary key columns: %d", len(id))
+	}
+	if err := r.beforeDelete(ctx, id); err != nil {
+		return err
+	}
+	res, err := r.hooked(ctx, "force_delete").ExecContext(ctx, r.deleteQ, id...)
+	if err != nil {
+		return fmt.Errorf("delete record: %w", classifyError(err))
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	r.untrack(ctx, id)
+
+	return nil
+}
+
+// withScope returns a copy of the relation with read queries rebuilt for the given scope.
+func (r *Relation[T]) withScope(scope trashedScope) *Relation[T] {
+	cp := *r
+	cp.scope = scope
+	cp.buildReadQueries()
+	return &cp
+}
+
+// buildReadQueries prebuilds read queries according to the soft delete scope.
+func (r *Relation[T]) buildReadQueries() {
+	where := r.scopeExpr("")
+	r.getOneQ = buildGetOneQuery(r.name, r.M, where...)
+	r.findByQ = buildFindByQuery(r.name, r.M, where...)
+	r.countByQ = buildCountByQuery(r.name, r.M, where...)
+}
+
+// updateColumns returns columns written by Update, the soft delete column is excluded.
+func (r *Relation[T]) updateColumns() ListColumnMeta {
+	cols := r.M.UpdateColumns()
+	if r.softDeleteColumn == "" {
+		return cols
+	}
+	res := make(ListColumnMeta, 0, len(cols))
+	for _, col := range cols {
+		if col.name != r.softDeleteColumn {
+			res = append(res, col)
+		}
+	}
+	return res
+}
+
+// writeScopeExpr returns soft delete conditions for updates, soft deleted entities are never updated.
+// The column is qualified with the given relation name if it is not empty.
+func (r *Relation[T]) writeScopeExpr(qualifier string) []string {
+	if r.softDeleteColumn == "" {
+		return nil
+	}
+	column := pq.QuoteIdentifier(r.softDeleteColumn)
+	if qualifier != "" {
+		column = qualifier + "." + column
+	}
+	return []string{column + " IS NULL"}
+}
+
+// scopeExpr returns soft delete conditions for the current scope,
+// the column is qualified with the given relation name if it is not empty.
+func (r *Relation[T]) scopeExpr(qualifier string) []string {
+	if r.softDeleteColumn == "" {
+		return nil
+	}
+	column := pq.QuoteIdentifier(r.softDeleteColumn)
+	if qualifier != "" {
+		column = qualifier + "." + column
+	}
+	switch r.scope {
+	case scopeWithTrashed:
+		return nil
+	case scopeOnlyTrashed:
+		return []string{column + " IS NOT NULL"}
+	default:
+		return []string{column + " IS NULL"}
+	}
+}
+
+// buildSoftDeleteQuery prebuilds a query to soft delete an entity
+func buildSoftDeleteQuery[T any](rel string, m *Metadata[T], column string) string {
+	qb := qbuilder.Update(rel)
+	qb.Set(pq.QuoteIdentifier(column), "now()")
+
+	for i, col := range m.PKColumns() {
+		qb.AndWhere(col.Identifier() + " = $" + strconv.Itoa(i+1))
+	}
+	qb.AndWhere(pq.QuoteIdentifier(column) + " IS NULL")
+
+	return qb.ToSQL()
+}
+
+// buildRestoreQuery prebuilds a query to restore a soft deleted entity
+func buildRestoreQuery[T any](rel string, m *Metadata[T], column string) string {
+	qb := qbuilder.Update(rel)
+	qb.Set(pq.QuoteIdentifier(column), "NULL")
+
+	for i, col := range m.PKColumns() {
+		qb.AndWhere(col.Identifier() + " = $" + strconv.Itoa(i+1))
+	}
+	qb.AndWhere(pq.QuoteIdentifier(column) + " IS NOT NULL")
+
+	return qb.ToSQL()
+}
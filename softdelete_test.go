@@ -0,0 +1,165 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type entitySoftDelete struct {
+	ID        int64      `db:"id"`
+	Name      string     `db:"name"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_SoftDelete(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	columns := []string{"id", "name", "deleted_at"}
+	now := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "entities" SET "deleted_at" = now() WHERE "id" = $1 AND "deleted_at" IS NULL`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name", "deleted_at" FROM "entities" WHERE "id" = $1 AND "deleted_at" IS NULL LIMIT 1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "Test Name", nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name", "deleted_at" FROM "entities" WHERE "deleted_at" IS NULL AND "name" = $1`)).
+		WithArgs("Test Name").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "Test Name", nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM "entities" WHERE "deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "name", "deleted_at" FROM "entities" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Test Name", now))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM "entities" WHERE "deleted_at" IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "entities" SET "deleted_at" = NULL WHERE "id" = $1 AND "deleted_at" IS NOT NULL`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1 WHERE "id" = $2 AND "deleted_at" IS NULL RETURNING "id", "name", "deleted_at"`)).
+		WithArgs("New Name", 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "New Name", nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1 WHERE "id" = $2 AND "deleted_at" IS NULL RETURNING "id", "name", "deleted_at"`)).
+		WithArgs("Test Name", 2).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "entities" SET "deleted_at" = now() WHERE "name" = $1 AND "deleted_at" IS NULL`)).
+		WithArgs("Test Name").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rel, err := NewRelation[entitySoftDelete]("entities", mockDB, SoftDelete[entitySoftDelete]("deleted_at"))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	if err = rel.Delete(ctx, 1); err != nil {
+		t.Fatalf("failed to soft delete: %v", err)
+	}
+	if _, err = rel.Find(ctx, 2); err != nil {
+		t.Fatalf("failed to find: %v", err)
+	}
+	if _, err = rel.FindBy(ctx, Cond{Eq("name", "Test Name")}, nil, Pagination{}); err != nil {
+		t.Fatalf("failed to find by: %v", err)
+	}
+	if _, err = rel.CountBy(ctx, nil); err != nil {
+		t.Fatalf("failed to count by: %v", err)
+	}
+	ent, err := rel.WithTrashed().Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to find with trashed: %v", err)
+	}
+	if ent.DeletedAt == nil {
+		t.Fatalf("expected deleted entity")
+	}
+	if _, err = rel.OnlyTrashed().CountBy(ctx, nil); err != nil {
+		t.Fatalf("failed to count only trashed: %v", err)
+	}
+	if err = rel.Restore(ctx, 1); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	// the soft delete column is not written by Update
+	if err = rel.Update(ctx, &entitySoftDelete{ID: 1, Name: "New Name", DeletedAt: &now}); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	// soft deleted entity is not updated
+	if err = rel.UpdateFields(ctx, &entitySoftDelete{ID: 2, Name: "Test Name"}, "name"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found for soft deleted entity, got: %v", err)
+	}
+	if _, err = rel.DeleteBy(ctx, Cond{Eq("name", "Test Name")}); err != nil {
+		t.Fatalf("failed to soft delete by: %v", err)
+	}
+	if err = rel.ForceDelete(ctx, 1); err != nil {
+		t.Fatalf("failed to force delete: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRelation_SoftDeleteUnknownColumn(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	if _, err = NewRelation[entitySoftDelete]("entities", mockDB, SoftDelete[entitySoftDelete]("removed_at")); err == nil {
+		t.Fatalf("expected error for unknown soft delete column")
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_SoftDeleteWrites(t *testing.T) {
+	type entitySoftDeleteEmail struct {
+		ID        int64      `db:"id"`
+		Email     string     `db:"email"`
+		Name      string     `db:"name"`
+		DeletedAt *time.Time `db:"deleted_at"`
+	}
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	columns := []string{"id", "email", "name", "deleted_at"}
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $2 WHERE "email" = $1 AND "deleted_at" IS NULL`)).
+		WithArgs("a@b.c", "New Name").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $2 WHERE "email" = $1 AND "deleted_at" IS NULL RETURNING "id", "email", "name", "deleted_at"`)).
+		WithArgs("a@b.c", "New Name").
+		WillReturnRows(sqlmock.NewRows(columns))
+	// the conflicting row is soft deleted, nothing is returned
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("email", "name", "deleted_at") VALUES ($1, $2, $3) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name" WHERE "entities"."deleted_at" IS NULL RETURNING "id", "email", "name", "deleted_at"`)).
+		WithArgs("a@b.c", "New Name", nil).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	rel, err := NewRelation[entitySoftDeleteEmail]("entities", mockDB, SoftDelete[entitySoftDeleteEmail]("deleted_at"))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+	cond := Cond{Eq("email", "a@b.c")}
+
+	if n, err := rel.UpdateBy(ctx, cond, map[string]any{"name": "New Name"}); err != nil || n != 0 {
+		t.Fatalf("unexpected update by result: %d, %v", n, err)
+	}
+	if items, err := rel.UpdateByReturning(ctx, cond, map[string]any{"name": "New Name"}); err != nil || len(items) != 0 {
+		t.Fatalf("unexpected update by returning result: %v, %v", items, err)
+	}
+	err = rel.Upsert(ctx, &entitySoftDeleteEmail{Email: "a@b.c", Name: "New Name"}, UpsertOptions{ConflictColumns: []string{"email"}})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found for soft deleted conflicting row, got: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package rel
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes of prepared statements invalidated on the server.
+const (
+	// pgCodeInvalidStatementName is returned when the prepared statement does not exist (e.g. after a failover).
+	pgCodeInvalidStatementName = "26000"
+	// pgCodeFeatureNotSupported is returned when a cached plan must not change result type after a schema change.
+	pgCodeFeatureNotSupported = "0A000"
+)
+
+// PrepareStatements enables lazy preparing of queries executed outside of transactions.
+// Prebuilt queries (insert, update, delete and find by primary key) stay prepared,
+// other queries (e.g. FindBy) are kept in an LRU cache of the given size per *sql.DB.
+// Statements invalidated on the server are prepared again.
+func PrepareStatements[T any](size int) Option[T] {
+	return func(r *Relation[T]) {
+		r.stmts = &stmtCaches{size: max(size, 0)}
+	}
+}
+
+// stmtCaches holds statement caches per *sql.DB, shared between relation copies.
+type stmtCaches struct {
+	size   int
+	caches sync.Map
+}
+
+// prepared returns the querier executing prepared statements if statement cache is enabled and q is a *sql.DB.
+func (r *Relation[T]) prepared(q Querier) Querier {
+	db, ok := q.(*sql.DB)
+	if !ok || r.stmts == nil {
+		return q
+	}
+	c, ok := r.stmts.caches.Load(db)
+	if !ok {
+		prebuilt := map[string]struct{}{r.insertQ: {}, r.updateQ: {}, r.deleteQ: {}, r.getOneQ: {}}
+		c, _ = r.stmts.caches.LoadOrStore(db, newStmtCache(db, r.stmts.size, prebuilt))
+	}
+	return &preparedQuerier{db: db, cache: c.(*stmtCache)}
+}
+
+// stmtEntry is a prepared statement of the cache.
+type stmtEntry struct {
+	query string
+	stmt  *sql.Stmt
+	// elem is the LRU list element, nil for prebuilt queries
+	elem *list.Element
+	// refs is the number of queries executing the statement
+	refs int
+	// evicted statements are closed when they are not referenced
+	evicted bool
+}
+
+// stmtCache caches prepared statements of a *sql.DB.
+type stmtCache struct {
+	db       *sql.DB
+	size     int
+	prebuilt map[string]struct{}
+
+	mu      sync.Mutex
+	entries map[string]*stmtEntry
+	lru     *list.List
+}
+
+// newStmtCache creates a statement cache keeping prebuilt queries and up to size other queries.
+func newStmtCache(db *sql.DB, size int, prebuilt map[string]struct{}) *stmtCache {
+	return &stmtCache{
+		db:       db,
+		size:     size,
+		prebuilt: prebuilt,
+		entries:  make(map[string]*stmtEntry),
+		lru:      list.New(),
+	}
+}
+
+// acquire returns the prepared statement of the query, preparing it if needed.
+// The entry must be released after use.
+func (c *stmtCache) acquire(ctx context.Context, query string) (*stmtEntry, error) {
+	if e := c.lookup(query); e != nil {
+		return e, nil
+	}
+	_, prebuilt := c.prebuilt[query]
+	if !prebuilt && c.size == 0 {
+		return nil, errors.New("statement cache is disabled")
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[query]; ok {
+		// prepared concurrently
+		_ = stmt.Close()
+		c.touch(e)
+		return e, nil
+	}
+	e := &stmtEntry{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = e
+	if !prebuilt {
+		e.elem = c.lru.PushFront(e)
+		for c.lru.Len() > c.size {
+			c.remove(c.lru.Back().Value.(*stmtEntry))
+		}
+	}
+	return e, nil
+}
+
+// lookup returns the cached entry of the query referenced.
+func (c *stmtCache) lookup(query string) *stmtEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[query]
+	if !ok {
+		return nil
+	}
+	c.touch(e)
+	return e
+}
+
+// touch references the entry and moves it to the front of the LRU list.
+func (c *stmtCache) touch(e *stmtEntry) {
+	e.refs++
+	if e.elem != nil {
+		c.lru.MoveToFront(e.elem)
+	}
+}
+
+// release dereferences the entry closing it if it is evicted.
+func (c *stmtCache) release(e *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		_ = e.stmt.Close()
+	}
+}
+
+// invalidate evicts the entry, so the query is prepared again.
+func (c *stmtCache) invalidate(e *stmtEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[e.query] == e {
+		c.remove(e)
+	}
+}
+
+// remove evicts the entry from the cache, it is closed once not referenced.
+func (c *stmtCache) remove(e *stmtEntry) {
+	delete(c.entries, e.query)
+	if e.elem != nil {
+		c.lru.Remove(e.elem)
+	}
+	e.evicted = true
+	if e.refs == 0 {
+		_ = e.stmt.Close()
+	}
+}
+
+// preparedQuerier is a Querier executing queries with prepared statements.
+type preparedQuerier struct {
+	db    *sql.DB
+	cache *stmtCache
+}
+
+func (p *preparedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return runPrepared(ctx, p, query,
+		func(stmt *sql.Stmt) (*sql.Rows, error) { return stmt.QueryContext(ctx, args...) },
+		func() (*sql.Rows, error) { return p.db.QueryContext(ctx, query, args...) })
+}
+
+func (p *preparedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	row, _ := runPrepared(ctx, p, query,
+		func(stmt *sql.Stmt) (*sql.Row, error) {
+			row := stmt.QueryRowContext(ctx, args...)
+			return row, row.Err()
+		},
+		func() (*sql.Row, error) { return p.db.QueryRowContext(ctx, query, args...), nil })
+	return row
+}
+
+func (p *preparedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return runPrepared(ctx, p, query,
+		func(stmt *sql.Stmt) (sql.Result, error) { return stmt.ExecContext(ctx, args...) },
+		func() (sql.Result, error) { return p.db.ExecContext(ctx, query, args...) })
+}
+
+// runPrepared executes the query with a prepared statement, the statement is prepared again once
+// if it was invalidated. The query is executed unprepared if it can not be prepared or cached.
+func runPrepared[R any](ctx context.Context, p *preparedQuerier, query string, exec func(*sql.Stmt) (R, error), direct func() (R, error)) (R, error) {
+	for attempt := 0; ; attempt++ {
+		e, err := p.cache.acquire(ctx, query)
+		if err != nil {
+			return direct()
+		}
+		res, err := exec(e.stmt)
+		if err != nil && attempt == 0 && isInvalidStmt(err) {
+			p.cache.invalidate(e)
+			p.cache.release(e)
+			continue
+		}
+		p.cache.release(e)
+		return res, err
+	}
+}
+
+// isInvalidStmt reports whether the error means that the prepared statement must be prepared again.
+func isInvalidStmt(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgCodeInvalidStatementName || pqErr.Code == pgCodeFeatureNotSupported
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_PrepareStatements(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"created", "updated", "id", "name"}
+	getOneQ := regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "id" = $1 LIMIT 1`)
+	findByQ := regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "name" = $1`)
+	countByQ := regexp.QuoteMeta(`SELECT COUNT(*) FROM "entities"`)
+
+	// prebuilt query is prepared once
+	getOne := mock.ExpectPrepare(getOneQ).WillBeClosed()
+	getOne.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows(columns).AddRow(created, created, 1, "Test Name"))
+	getOne.ExpectQuery().WithArgs(2).WillReturnError(&pq.Error{Code: pgCodeInvalidStatementName})
+	// invalidated statement is prepared again
+	mock.ExpectPrepare(getOneQ).
+		ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows(columns).AddRow(created, created, 2, "Test Name"))
+	// dynamic queries are evicted from the LRU
+	mock.ExpectPrepare(findByQ).WillBeClosed().
+		ExpectQuery().WithArgs("Test Name").WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectPrepare(countByQ).
+		ExpectQuery().WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB, PrepareStatements[entitySerialID](1))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err = rel.Find(ctx, 1); err != nil {
+		t.Fatalf("failed to find: %v", err)
+	}
+	if _, err = rel.Find(ctx, 2); err != nil {
+		t.Fatalf("failed to find after invalidation: %v", err)
+	}
+	if _, err = rel.FindBy(ctx, Cond{Eq("name", "Test Name")}, nil, Pagination{}); err != nil {
+		t.Fatalf("failed to find by: %v", err)
+	}
+	if _, err = rel.CountBy(ctx, nil); err != nil {
+		t.Fatalf("failed to count by: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
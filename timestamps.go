@@ -0,0 +1,66 @@
+package rel
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Clock sets the function used to get the current time for auto time columns, time.Now by default.
+func Clock[T any](now func() time.Time) Option[T] {
+	return func(r *Relation[T]) {
+		r.now = now
+	}
+}
+
+// touchInsert sets zero auto create and auto update time columns of the entity to the current time.
+func (r *Relation[T]) touchInsert(entity *T) {
+	if len(r.M.AutoCreateTimeColumns()) == 0 && len(r.M.AutoUpdateTimeColumns()) == 0 {
+		return
+	}
+	now := r.now()
+	for _, col := range r.M.AutoCreateTimeColumns() {
+		setTimeField(col, entity, now, true)
+	}
+	for _, col := range r.M.AutoUpdateTimeColumns() {
+		setTimeField(col, entity, now, true)
+	}
+}
+
+// touchUpdate sets auto update time columns of the entity to the current time.
+func (r *Relation[T]) touchUpdate(entity *T) {
+	if len(r.M.AutoUpdateTimeColumns()) == 0 {
+		return
+	}
+	now := r.now()
+	for _, col := range r.M.AutoUpdateTimeColumns() {
+		setTimeField(col, entity, now, false)
+	}
+}
+
+// withAutoUpdateColumns appends missing auto update time columns to the given columns.
+func (r *Relation[T]) withAutoUpdateColumns(columns []string) []string {
+	for _, name := range r.M.AutoUpdateTimeColumns().Names() {
+		if !slices.Contains(columns, name) {
+			columns = append(slices.Clip(columns), name)
+		}
+	}
+	return columns
+}
+
+// setTimeField sets a time.Time or *time.Time field of the entity, only zero fields are set if onlyZero is true.
+func setTimeField[T any](col *ColumnMeta, entity *T, now time.Time, onlyZero bool) {
+	v := reflect.ValueOf(entity).Elem()
+	for _, index := range col.path {
+		v = v.Field(index)
+	}
+	if onlyZero && !v.IsZero() {
+		return
+	}
+	switch v.Type() {
+	case timeType:
+		v.Set(reflect.ValueOf(now))
+	case timePtrType:
+		v.Set(reflect.ValueOf(&now))
+	}
+}
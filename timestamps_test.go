@@ -0,0 +1,84 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+type entityAutoTime struct {
+	ID        int64      `db:"id"`
+	Name      string     `db:"name"`
+	CreatedAt time.Time  `db:"created_at,autoCreateTime"`
+	UpdatedAt *time.Time `db:"updated_at,autoUpdateTime"`
+}
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelation_AutoTime(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	columns := []string{"id", "name", "created_at", "updated_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("name", "created_at", "updated_at") VALUES ($1, $2, $3) RETURNING "id", "name", "created_at", "updated_at"`)).
+		WithArgs("Test Name", created, &created).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Test Name", created, created))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1, "updated_at" = $2 WHERE "id" = $3 RETURNING "id", "name", "created_at", "updated_at"`)).
+		WithArgs("New Name", &updated, 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "New Name", created, updated))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1, "updated_at" = $2 WHERE "id" = $3 RETURNING "id", "name", "created_at", "updated_at"`)).
+		WithArgs("Newer Name", &updated, 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "Newer Name", created, updated))
+
+	now := created
+	rel, err := NewRelation[entityAutoTime]("entities", mockDB, Clock[entityAutoTime](func() time.Time { return now }))
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+	ctx := context.Background()
+
+	ent := entityAutoTime{Name: "Test Name"}
+	if err = rel.Insert(ctx, &ent); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+	if !ent.CreatedAt.Equal(created) {
+		t.Fatalf("unexpected CreatedAt: %v", ent.CreatedAt)
+	}
+
+	now = updated
+	ent.Name = "New Name"
+	if err = rel.Update(ctx, &ent); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if ent.UpdatedAt == nil || !ent.UpdatedAt.Equal(updated) {
+		t.Fatalf("unexpected UpdatedAt: %v", ent.UpdatedAt)
+	}
+
+	ent.Name = "Newer Name"
+	if err = rel.UpdateFields(ctx, &ent, "name"); err != nil {
+		t.Fatalf("failed to update fields: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRelation_AutoTimeInvalidField(t *testing.T) {
+	type invalid struct {
+		ID        int64 `db:"id"`
+		CreatedAt int64 `db:"created_at,autoCreateTime"`
+	}
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	if _, err = NewRelation[invalid]("entities", mockDB); err == nil {
+		t.Fatalf("expected error for invalid auto time field")
+	}
+}
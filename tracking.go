@@ -0,0 +1,249 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Change represents a change of a column value.
+type Change struct {
+	Old any
+	New any
+}
+
+type trackerCtxKey struct{}
+
+// tracker holds snapshots of entities tracked in a unit of work.
+// A child tracker is created for every TxManager transaction or savepoint,
+// its snapshots are merged into the parent on commit and discarded on rollback.
+type tracker struct {
+	parent *tracker
+
+	mu        sync.Mutex
+	snapshots map[string][]any
+	// removed hides parent snapshots removed by the child
+	removed map[string]struct{}
+}
+
+func newTracker(parent *tracker) *tracker {
+	return &tracker{parent: parent, snapshots: make(map[string][]any), removed: make(map[string]struct{})}
+}
+
+// WithTracker returns a context with a new unit of work holding snapshots of tracked entities.
+// Snapshots live as long as the unit of work, e.g. a request, and are visible only to queries using the context.
+// Snapshots taken inside a TxManager transaction are discarded if it is rolled back.
+func WithTracker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trackerCtxKey{}, newTracker(trackerFromContext(ctx)))
+}
+
+// trackerFromContext returns the tracker of the unit of work stored in the context, nil if there is none.
+func trackerFromContext(ctx context.Context) *tracker {
+	t, _ := ctx.Value(trackerCtxKey{}).(*tracker)
+	return t
+}
+
+// withChildTracker returns a context with a child of the context tracker and a function merging it into the parent.
+func withChildTracker(ctx context.Context) (context.Context, func()) {
+	parent := trackerFromContext(ctx)
+	if parent == nil {
+		return ctx, func() {}
+	}
+	child := newTracker(parent)
+	return context.WithValue(ctx, trackerCtxKey{}, child), child.merge
+}
+
+func (t *tracker) load(key string) ([]any, bool) {
+	for ; t != nil; t = t.parent {
+		t.mu.Lock()
+		v, ok := t.snapshots[key]
+		_, removed := t.removed[key]
+		t.mu.Unlock()
+		if ok {
+			return v, true
+		}
+		if removed {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+func (t *tracker) store(key string, v []any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.snapshots[key] = v
+	delete(t.removed, key)
+}
+
+func (t *tracker) remove(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.snapshots, key)
+	t.removed[key] = struct{}{}
+}
+
+// merge applies snapshots of the child tracker to the parent.
+func (t *tracker) merge() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.removed {
+		t.parent.remove(key)
+	}
+	for key, v := range t.snapshots {
+		t.parent.store(key, v)
+	}
+}
+
+// Tracking enables automatic snapshots of entities loaded by Find, FindOneBy, FindBy and Insert
+// with a context of a unit of work, see WithTracker.
+func Tracking[T any](r *Relation[T]) {
+	r.tracking = true
+}
+
+// Track remembers current column values of the entity in the unit of work of the context.
+// Update of a tracked entity with the context writes only the changed columns.
+func (r *Relation[T]) Track(ctx context.Context, entity *T) error {
+	t := trackerFromContext(ctx)
+	if t == nil {
+		return fmt.Errorf("track: no unit of work in context, use WithTracker")
+	}
+	t.store(r.snapshotKey(r.pkValues(entity)), r.snapshot(entity))
+	return nil
+}
+
+// Untrack forgets the snapshot of the entity in the unit of work of the context.
+func (r *Relation[T]) Untrack(ctx context.Context, entity *T) {
+	r.untrack(ctx, r.pkValues(entity))
+}
+
+// Changes returns changed columns of the entity tracked in the unit of work of the context
+// with their old and new values. The second return value is false if the entity is not tracked.
+func (r *Relation[T]) Changes(ctx context.Context, entity *T) (map[string]Change, bool) {
+	t := trackerFromContext(ctx)
+	if t == nil {
+		return nil, false
+	}
+	old, ok := t.load(r.snapshotKey(r.pkValues(entity)))
+	if !ok {
+		return nil, false
+	}
+	cur := getFieldsValues(r.updateColumns().Names(), r.M, entity)
+
+	changes := make(map[string]Change)
+	for i, name := range r.updateColumns().Names() {
+		if !valuesEqual(old[i], cur[i]) {
+			changes[name] = Change{Old: old[i], New: cur[i]}
+		}
+	}
+	return changes, true
+}
+
+// UpdateChanges writes only the changed columns of the tracked entity and returns the changes.
+// Nothing is written if there are no changes.
+func (r *Relation[T]) UpdateChanges(ctx context.Context, entity *T) (map[string]Change, error) {
+	if err := beforeUpdate(ctx, entity); err != nil {
+		return nil, err
+	}
+	changes, err := r.updateChanges(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	return changes, afterUpdate(ctx, entity)
+}
+
+// updateChanges updates changed columns of the tracked entity without calling hooks.
+func (r *Relation[T]) updateChanges(ctx context.Context, entity *T) (map[string]Change, error) {
+	changes, ok := r.Changes(ctx, entity)
+	if !ok {
+		return nil, fmt.Errorf("entity is not tracked")
+	}
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	columns := make([]string, 0, len(changes))
+	for _, name := range r.updateColumns().Names() {
+		if _, ok := changes[name]; ok {
+			columns = append(columns, name)
+		}
+	}
+	if err := r.updateFields(ctx, entity, columns); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// autoTrack takes a snapshot of the entity if tracking is enabled and the context has a unit of work.
+func (r *Relation[T]) autoTrack(ctx context.Context, entity *T) {
+	if r.tracking {
+		_ = r.Track(ctx, entity)
+	}
+}
+
+// retrack refreshes the snapshot of the entity if it is tracked or tracking is enabled.
+func (r *Relation[T]) retrack(ctx context.Context, entity *T) {
+	if r.tracking || r.isTracked(ctx, entity) {
+		_ = r.Track(ctx, entity)
+	}
+}
+
+// untrack forgets the snapshot of the entity with the given primary key values.
+func (r *Relation[T]) untrack(ctx context.Context, pk []any) {
+	if t := trackerFromContext(ctx); t != nil {
+		t.remove(r.snapshotKey(pk))
+	}
+}
+
+// isTracked reports whether the entity has a snapshot in the unit of work of the context.
+func (r *Relation[T]) isTracked(ctx context.Context, entity *T) bool {
+	t := trackerFromContext(ctx)
+	if t == nil {
+		return false
+	}
+	_, ok := t.load(r.snapshotKey(r.pkValues(entity)))
+	return ok
+}
+
+// pkValues returns primary key values of the entity.
+func (r *Relation[T]) pkValues(entity *T) []any {
+	return getFieldsValues(r.M.PKColumns().Names(), r.M, entity)
+}
+
+// snapshotKey returns a key of the snapshot by relation name and primary key values.
+func (r *Relation[T]) snapshotKey(pk []any) string {
+	return fmt.Sprintf("%s:%#v", r.name, pk)
+}
+
+// snapshot returns copies of the update columns values of the entity.
+func (r *Relation[T]) snapshot(entity *T) []any {
+	values := getFieldsValues(r.updateColumns().Names(), r.M, entity)
+	for i, val := range values {
+		values[i] = copyValue(val)
+	}
+	return values
+}
+
+// copyValue copies slice values so in-place modifications are detected.
+func copyValue(val any) any {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice || v.IsNil() {
+		return val
+	}
+	cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(cp, v)
+	return cp.Interface()
+}
+
+// valuesEqual compares column values, times are compared by instant.
+func valuesEqual(a, b any) bool {
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Equal(tb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,156 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_Tracking(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	columns := []string{"created", "updated", "id", "name"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(now, now, 1, "Old Name"))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1 WHERE "id" = $2 RETURNING "created", "updated", "id", "name"`)).
+		WithArgs("New Name", 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(now, now, 1, "New Name"))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB, Tracking[entitySerialID])
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	ctx := WithTracker(context.Background())
+	ent, err := rel.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to find entity: %v", err)
+	}
+	changes, ok := rel.Changes(ctx, &ent)
+	if !ok || len(changes) != 0 {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+
+	ent.Name = "New Name"
+	ent.Created = ent.Created.Local()
+	changes, err = rel.UpdateChanges(ctx, &ent)
+	if err != nil {
+		t.Fatalf("failed to update entity: %v", err)
+	}
+	if len(changes) != 1 || changes["name"] != (Change{Old: "Old Name", New: "New Name"}) {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+
+	// nothing changed since the last update, no query expected
+	if err = rel.Update(ctx, &ent); err != nil {
+		t.Fatalf("failed to update entity: %v", err)
+	}
+
+	rel.Untrack(ctx, &ent)
+	if _, ok = rel.Changes(ctx, &ent); ok {
+		t.Fatalf("expected entity to be untracked")
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRelation_UpdateChangesNotTracked(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	ent := entitySerialID{ID: 1}
+	if _, err = rel.UpdateChanges(context.Background(), &ent); err == nil {
+		t.Fatalf("expected error for not tracked entity")
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRelation_TrackingRollback(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	now := time.Now()
+	columns := []string{"created", "updated", "id", "name"}
+	updateQ := regexp.QuoteMeta(`UPDATE "entities" SET "name" = $1 WHERE "id" = $2 RETURNING "created", "updated", "id", "name"`)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "created", "updated", "id", "name" FROM "entities" WHERE "id" = $1 LIMIT 1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(now, now, 1, "Old Name"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(updateQ).
+		WithArgs("New Name", 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(now, now, 1, "New Name"))
+	mock.ExpectRollback()
+	// the snapshot taken in the rolled back transaction is discarded, the change is written again
+	mock.ExpectQuery(updateQ).
+		WithArgs("New Name", 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(now, now, 1, "New Name"))
+
+	rel, err := NewRelation[entitySerialID]("entities", mockDB, Tracking[entitySerialID])
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	ctx := WithTracker(context.Background())
+	ent, err := rel.Find(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed to find entity: %v", err)
+	}
+	ent.Name = "New Name"
+	ent.Created = ent.Created.Local()
+
+	rollback := errors.New("rollback")
+	err = NewTxManager(mockDB).RunInTx(ctx, func(ctx context.Context) error {
+		if err := rel.Update(ctx, &ent); err != nil {
+			return err
+		}
+		return rollback
+	}, nil)
+	if !errors.Is(err, rollback) {
+		t.Fatalf("expected rollback error, got: %v", err)
+	}
+
+	if err = rel.Update(ctx, &ent); err != nil {
+		t.Fatalf("failed to update entity: %v", err)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRelation_TrackWithoutTracker(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	rel, err := NewRelation[entitySerialID]("entities", mockDB)
+	if err != nil {
+		t.Fatalf("failed to create relation: %v", err)
+	}
+
+	ent := entitySerialID{ID: 1}
+	if err = rel.Track(context.Background(), &ent); err == nil {
+		t.Fatalf("expected error without unit of work")
+	}
+	if rel.isTracked(context.Background(), &ent) {
+		t.Fatalf("expected entity not to be tracked")
+	}
+}
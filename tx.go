@@ -0,0 +1,139 @@
+package rel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type txCtxKey struct{}
+
+// txState is an active transaction stored in the context.
+type txState struct {
+	db *sql.DB
+	tx *sql.Tx
+	// depth is the number of nested RunInTx calls (savepoints).
+	depth int
+}
+
+// TxFromContext returns the transaction stored in the context by TxManager.RunInTx.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	st, ok := ctx.Value(txCtxKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return st.tx, true
+}
+
+// txFromContext returns the transaction stored in the context if it was started on the given db.
+func txFromContext(ctx context.Context, db *sql.DB) (*sql.Tx, bool) {
+	st, ok := ctx.Value(txCtxKey{}).(*txState)
+	if !ok || st.db != db {
+		return nil, false
+	}
+	return st.tx, true
+}
+
+// TxManager runs functions in a transaction propagated through the context.
+// Every Relation created on the same *sql.DB uses the transaction from the context automatically.
+type TxManager struct {
+	db *sql.DB
+	// retry re-runs failed transactions, not retried if nil
+	retry *RetryPolicy
+}
+
+// NewTxManager creates a new TxManager instance for the given database.
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// RunInTx runs fn in a transaction. The transaction is committed if fn returns nil and rolled back otherwise.
+// Nested calls reuse the outer transaction and are isolated with savepoints, opts are ignored for them.
+// Failed transactions are retried if the manager has a retry policy, see WithRetry.
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts *sql.TxOptions) error {
+	if st, ok := ctx.Value(txCtxKey{}).(*txState); ok && st.db == m.db {
+		return m.runInSavepoint(ctx, st, fn)
+	}
+	if m.retry != nil {
+		return m.retry.runWithRetry(ctx, func() error {
+			return m.runInTx(ctx, fn, opts)
+		})
+	}
+	return m.runInTx(ctx, fn, opts)
+}
+
+// runInTx runs fn in a new transaction.
+func (m *TxManager) runInTx(ctx context.Context, fn func(ctx context.Context) error, opts *sql.TxOptions) error {
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	st := &txState{db: m.db, tx: tx}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	// snapshots tracked in the transaction are kept only if it is committed
+	txCtx, merge := withChildTracker(context.WithValue(ctx, txCtxKey{}, st))
+	if err = fn(txCtx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("rollback tx: %w", rbErr))
+		}
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return &errCommit{err: classifyError(err)}
+	}
+	merge()
+	return nil
+}
+
+// errCommit is returned when COMMIT fails, the transaction may have been committed
+// if the connection failed after COMMIT was sent.
+type errCommit struct {
+	err error
+}
+
+func (e *errCommit) Error() string {
+	return "commit tx: " + e.err.Error()
+}
+
+func (e *errCommit) Unwrap() error {
+	return e.err
+}
+
+// runInSavepoint runs fn inside a savepoint of the outer transaction.
+func (m *TxManager) runInSavepoint(ctx context.Context, outer *txState, fn func(ctx context.Context) error) error {
+	st := &txState{db: outer.db, tx: outer.tx, depth: outer.depth + 1}
+	sp := "sp_" + strconv.Itoa(st.depth)
+
+	if _, err := st.tx.ExecContext(ctx, "SAVEPOINT "+sp); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = st.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp)
+			panic(p)
+		}
+	}()
+
+	spCtx, merge := withChildTracker(context.WithValue(ctx, txCtxKey{}, st))
+	if err := fn(spCtx); err != nil {
+		if _, rbErr := st.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback to savepoint: %w", rbErr))
+		}
+		return err
+	}
+	if _, err := st.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+sp); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	merge()
+	return nil
+}
@@ -0,0 +1,116 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestTxManager_RunInTx(t *testing.T) {
+	errFail := errors.New("fail")
+
+	tests := []struct {
+		name   string
+		expect func(mock sqlmock.Sqlmock)
+		run    func(ctx context.Context, m *TxManager, rel *Relation[entitySerialID]) error
+		err    error
+	}{
+		{
+			name: "commit",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			run: func(ctx context.Context, m *TxManager, rel *Relation[entitySerialID]) error {
+				return m.RunInTx(ctx, func(ctx context.Context) error {
+					if _, ok := TxFromContext(ctx); !ok {
+						return errors.New("no tx in context")
+					}
+					return rel.Delete(ctx, 1)
+				}, nil)
+			},
+		},
+		{
+			name: "rollback",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			run: func(ctx context.Context, m *TxManager, rel *Relation[entitySerialID]) error {
+				return m.RunInTx(ctx, func(ctx context.Context) error {
+					if err := rel.Delete(ctx, 1); err != nil {
+						return err
+					}
+					return errFail
+				}, nil)
+			},
+			err: errFail,
+		},
+		{
+			name: "nested savepoints",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT sp_1`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`ROLLBACK TO SAVEPOINT sp_1`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`SAVEPOINT sp_1`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "entities" WHERE "id" = $1`)).
+					WithArgs(2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(`RELEASE SAVEPOINT sp_1`)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			run: func(ctx context.Context, m *TxManager, rel *Relation[entitySerialID]) error {
+				return m.RunInTx(ctx, func(ctx context.Context) error {
+					err := m.RunInTx(ctx, func(ctx context.Context) error {
+						if err := rel.Delete(ctx, 1); err != nil {
+							return err
+						}
+						return errFail
+					}, nil)
+					if !errors.Is(err, errFail) {
+						return errors.New("expected nested error")
+					}
+					return m.RunInTx(ctx, func(ctx context.Context) error {
+						return rel.Delete(ctx, 2)
+					}, nil)
+				}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock db: %v", err)
+			}
+			tt.expect(mock)
+
+			rel, err := NewRelation[entitySerialID]("entities", mockDB)
+			if err != nil {
+				t.Fatalf("failed to create relation: %v", err)
+			}
+
+			err = tt.run(context.Background(), NewTxManager(mockDB), rel)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package rel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/slmder/rel/qbuilder"
+)
+
+// UpsertOptions represents the options of Relation.Upsert.
+type UpsertOptions struct {
+	// ConflictColumns are the conflict target columns, primary key columns by default.
+	// They must be insert columns, so upsert on primary key conflict requires the generated primary key strategy.
+	ConflictColumns []string
+	// UpdateColumns are the columns updated on conflict, Metadata.UpdateColumns() by default.
+	UpdateColumns []string
+}
+
+// Upsert inserts an entity or updates it if a row with the same conflict columns already exists.
+// If there are no columns to update or the conflicting row is soft deleted, the row is left intact and ErrNotFound is returned.
+// BeforeInsert and AfterInsert hooks of the entity are called in both cases.
+func (r *Relation[T]) Upsert(ctx context.Context, entity *T, opts UpsertOptions) error {
+	query, err := r.upsertQuery(opts)
+	if err != nil {
+		return err
+	}
+	if err = beforeInsert(ctx, entity); err != nil {
+		return err
+	}
+	r.touchInsert(entity)
+	r.touchUpdate(entity)
+	args := getFieldsValues(r.M.InsertColumns().Names(), r.M, entity)
+	row := r.hooked(ctx, "upsert").QueryRowContext(ctx, query, args...)
+	if err = classifyError(scanRow(row.Scan, r.M, entity)); err != nil {
+		return err
+	}
+
+	return afterInsert(ctx, entity)
+}
+
+// upsertQuery returns the prebuilt upsert query for the default options,
+// otherwise builds and caches a query for the given options.
+func (r *Relation[T]) upsertQuery(opts UpsertOptions) (string, error) {
+	if len(opts.ConflictColumns) == 0 && len(opts.UpdateColumns) == 0 {
+		if r.upsertQ == "" {
+			return "", checkConflictColumns(r.M, r.M.PKColumns())
+		}
+		return r.upsertQ, nil
+	}
+	key := "upsert:" + strings.Join(opts.ConflictColumns, ",") + ":" + strings.Join(opts.UpdateColumns, ",")
+	if q, ok := r.queries.Load(key); ok {
+		return q.(string), nil
+	}
+
+	conflict, update := r.M.PKColumns(), r.updateColumns()
+	var err error
+	if len(opts.ConflictColumns) > 0 {
+		if conflict, err = r.M.ColumnsByNames(opts.ConflictColumns...); err != nil {
+			return "", err
+		}
+	}
+	if len(opts.UpdateColumns) > 0 {
+		if update, err = r.M.ColumnsByNames(opts.UpdateColumns...); err != nil {
+			return "", err
+		}
+	}
+	if err = checkConflictColumns(r.M, conflict); err != nil {
+		return "", err
+	}
+	q := buildUpsertQuery(r.name, r.M, conflict, update, r.writeScopeExpr(r.name)...)
+	r.queries.Store(key, q)
+
+	return q, nil
+}
+
+// checkConflictColumns checks that the conflict columns are inserted, otherwise the conflict never fires.
+func checkConflictColumns[T any](m *Metadata[T], conflict ListColumnMeta) error {
+	for _, col := range conflict {
+		if !slices.Contains(m.InsertColumns(), col) {
+			return fmt.Errorf("upsert: conflict column %s is not an insert column", col.name)
+		}
+	}
+	return nil
+}
+
+// buildUpsertQuery builds a query to insert an entity or update given columns on conflict
+// if the conflicting row matches the where conditions
+func buildUpsertQuery[T any](rel string, m *Metadata[T], conflict, update ListColumnMeta, where ...string) string {
+	qb := qbuilder.Insert(rel)
+	qb.Columns(m.InsertColumns().Identifiers()...)
+	qb.Values(getArgsPlaceholders(len(m.InsertColumns())))
+	qb.OnConflict(strings.Join(conflict.Identifiers(), ", "), false)
+
+	set := make(map[string]string, len(update))
+	for _, col := range update {
+		if slices.Contains(conflict, col) {
+			continue
+		}
+		set[col.Identifier()] = "EXCLUDED." + col.Identifier()
+	}
+	if v := m.VersionColumn(); v != nil {
+		set[v.Identifier()] = rel + "." + v.Identifier() + " + 1"
+	}
+	if len(set) > 0 {
+		qb.DoUpdate(set).DoUpdateWhere(where...)
+	} else {
+		qb.DoNothing()
+	}
+	qb.Returning(m.Columns().Identifiers()...)
+
+	return qb.ToSQL()
+}
@@ -0,0 +1,109 @@
+package rel
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+//goland:noinspection SqlNoDataSourceInspection
+func TestRelation_Upsert(t *testing.T) {
+	now := time.Now()
+
+	t.Run("primary key conflict", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock db: %v", err)
+		}
+		eq := mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("created", "updated", "id_a", "id_b", "name") VALUES ($1, $2, $3, $4, $5) ON CONFLICT ("id_a", "id_b") DO UPDATE SET "created" = EXCLUDED."created", "name" = EXCLUDED."name", "updated" = EXCLUDED."updated" RETURNING "created", "updated", "id_a", "id_b", "name"`))
+		eq.WithArgs(now, now, 1, 2, "Test Name")
+		eq.WillReturnRows(
+			sqlmock.NewRows([]string{"created", "updated", "id_a", "id_b", "name"}).
+				AddRow(now, now, 1, 2, "Test Name"),
+		)
+
+		rel, err := NewRelation[entityCompositeID]("entities", mockDB, PKStrategyGenerated, PK[entityCompositeID]("id_a", "id_b"))
+		if err != nil {
+			t.Fatalf("failed to create relation: %v", err)
+		}
+
+		ent := entityCompositeID{IDA: 1, IDB: 2, Name: "Test Name", TimeStamps: TimeStamps{Created: now, Updated: now}}
+		if err = rel.Upsert(context.Background(), &ent, UpsertOptions{}); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("custom conflict columns", func(t *testing.T) {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock db: %v", err)
+		}
+		eq := mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "entities" ("created", "updated", "name") VALUES ($1, $2, $3) ON CONFLICT ("name") DO UPDATE SET "updated" = EXCLUDED."updated" RETURNING "created", "updated", "id", "name"`))
+		eq.WithArgs(now, now, "Test Name")
+		eq.WillReturnRows(
+			sqlmock.NewRows([]string{"created", "updated", "id", "name"}).
+				AddRow(now, now, 7, "Test Name"),
+		)
+
+		rel, err := NewRelation[entitySerialID]("entities", mockDB)
+		if err != nil {
+			t.Fatalf("failed to create relation: %v", err)
+		}
+
+		opts := UpsertOptions{ConflictColumns: []string{"name"}, UpdateColumns: []string{"updated"}}
+		ent := entitySerialID{Name: "Test Name", TimeStamps: TimeStamps{Created: now, Updated: now}}
+		if err = rel.Upsert(context.Background(), &ent, opts); err != nil {
+			t.Fatalf("failed to upsert: %v", err)
+		}
+		if ent.ID != 7 {
+			t.Fatalf("unexpected ID: %d", ent.ID)
+		}
+		if _, ok := rel.queries.Load("upsert:name:updated"); !ok {
+			t.Fatalf("expected upsert query to be cached")
+		}
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		mockDB, _, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock db: %v", err)
+		}
+		rel, err := NewRelation[entitySerialID]("entities", mockDB)
+		if err != nil {
+			t.Fatalf("failed to create relation: %v", err)
+		}
+
+		ent := entitySerialID{Name: "Test Name"}
+		if err = rel.Upsert(context.Background(), &ent, UpsertOptions{ConflictColumns: []string{"email"}}); err == nil {
+			t.Fatalf("expected error for unknown column")
+		}
+	})
+
+	t.Run("conflict column is not inserted", func(t *testing.T) {
+		mockDB, _, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to create mock db: %v", err)
+		}
+		rel, err := NewRelation[entitySerialID]("entities", mockDB)
+		if err != nil {
+			t.Fatalf("failed to create relation: %v", err)
+		}
+
+		ent := entitySerialID{Name: "Test Name"}
+		if err = rel.Upsert(context.Background(), &ent, UpsertOptions{}); err == nil {
+			t.Fatalf("expected error for sequence primary key conflict")
+		}
+		if err = rel.Upsert(context.Background(), &ent, UpsertOptions{ConflictColumns: []string{"id"}}); err == nil {
+			t.Fatalf("expected error for sequence primary key conflict")
+		}
+	})
+}